@@ -0,0 +1,372 @@
+package node
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNoArticle is returned by ExtractArticle when no content can be found.
+var ErrNoArticle = errors.New("no article content found")
+
+// Article is the main content of a page found by ExtractArticle.
+type Article struct {
+	// Node is the element holding the article content, with boilerplate removed.
+	Node Node
+	// Title is the article heading, or the page title without the site name.
+	Title string
+	// Byline is the author as shown on the page, without a leading "By".
+	Byline string
+	// Published is the publication time, or the zero time if it is unknown.
+	Published time.Time
+}
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveHints      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeHints      = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	bylineHints        = regexp.MustCompile(`(?i)byline|author|dateline|writtenby|p-author`)
+	titleSeparators    = regexp.MustCompile(`\s+[|\-–—\\/>»:]+\s+`)
+)
+
+// boilerplateTags are removed from the article with their content.
+var boilerplateTags = Tags("script", "style", "noscript", "template", "iframe", "form", "nav", "aside", "footer",
+	"button", "input", "select", "textarea", "object", "embed")
+
+// ExtractArticle finds the main content of the page containing n in the manner of Readability:
+// paragraphs score their ancestors by length and commas, elements are weighted by their tag and
+// by class and id hints, scores are reduced by link density, and the best scoring element is the article.
+//
+// Boilerplate is stripped from the article element in place: scripts, forms, navigation, hidden
+// elements, and blocks which are mostly links or lack text, particularly those hinted as comments,
+// ads or sharing widgets. The rest of the tree is left as is.
+func ExtractArticle(n HtmlNode) (*Article, error) {
+	root := n.Raw()
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	top := topCandidate(root)
+	if top == nil {
+		return nil, ErrNoArticle
+	}
+	var doc HtmlNode = NewNode(root)
+	if d, ok := n.(*Document); ok {
+		doc = d
+	}
+	meta := Metadata(doc)
+	// Read the details before cleaning removes the byline and other elements holding them.
+	article := &Article{Node: NewNode(top), Title: articleTitle(top, meta), Byline: byline(root, meta), Published: published(root, top)}
+	cleanArticle(top)
+	invalidateIndex(top)
+	return article, nil
+}
+
+func classWeight(n *html.Node) (weight float64) {
+	for _, key := range []string{"class", "id"} {
+		if v, _ := attributes(n.Attr).Get(key); v != "" {
+			if negativeHints.MatchString(v) {
+				weight -= 25
+			}
+			if positiveHints.MatchString(v) {
+				weight += 25
+			}
+		}
+	}
+	return
+}
+
+func tagWeight(n *html.Node) float64 {
+	switch n.Data {
+	case "div", "article", "main":
+		return 5
+	case "pre", "td", "blockquote":
+		return 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+// isUnlikely reports whether the element is unlikely to hold content and is skipped when scoring.
+func isUnlikely(n *html.Node) bool {
+	if n.Data == "body" || n.Data == "article" || n.Data == "main" {
+		return false
+	}
+	if skip(n) || boilerplateTags.IsMatch(NewNode(n)) {
+		return true
+	}
+	class, _ := attributes(n.Attr).Get("class")
+	id, _ := attributes(n.Attr).Get("id")
+	hints := class + " " + id
+	if role, _ := attributes(n.Attr).Get("role"); role == "complementary" || role == "navigation" {
+		return true
+	}
+	return unlikelyCandidates.MatchString(hints) && !maybeCandidate.MatchString(hints)
+}
+
+func topCandidate(root *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode || n.Data == "html" {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = tagWeight(n) + classWeight(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || isUnlikely(c) {
+				continue
+			}
+			switch c.Data {
+			case "p", "pre", "td", "blockquote":
+				text := strings.Join(strings.Fields(NewNode(c).GetText()), " ")
+				if len(text) >= 25 {
+					score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text)/100), 3)
+					addScore(c.Parent, score)
+					if c.Parent != nil {
+						addScore(c.Parent.Parent, score/2)
+						if c.Parent.Parent != nil {
+							addScore(c.Parent.Parent.Parent, score/3)
+						}
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+
+	var top *html.Node
+	var best float64
+	for _, c := range candidates {
+		if score := scores[c] * (1 - linkDensity(c)); top == nil || score > best {
+			top, best = c, score
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	// A parent holding the top candidate and similarly scored content is a better choice.
+	for p := top.Parent; p != nil && p.Data != "body" && p.Data != "html"; p = p.Parent {
+		if score, ok := scores[p]; ok && score*(1-linkDensity(p)) >= best*0.75 {
+			top, best = p, score*(1-linkDensity(p))
+		}
+	}
+	return top
+}
+
+// linkDensity returns the share of the text of n inside links.
+func linkDensity(n *html.Node) float64 {
+	var total, links int
+	for c := range n.Descendants() {
+		if c.Type != html.TextNode {
+			continue
+		}
+		l := len(strings.TrimSpace(c.Data))
+		total += l
+		for p := c.Parent; p != nil && p != n; p = p.Parent {
+			if p.Type == html.ElementNode && p.Data == "a" {
+				links += l
+				break
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(links) / float64(total)
+}
+
+func cleanArticle(top *html.Node) {
+	var remove []*html.Node
+	var conditional []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.CommentNode:
+				remove = append(remove, c)
+				continue
+			case c.Type != html.ElementNode:
+				continue
+			case skip(c), boilerplateTags.IsMatch(NewNode(c)), bylineElement(c):
+				remove = append(remove, c)
+				continue
+			}
+			switch c.Data {
+			case "div", "section", "ul", "ol", "table", "header", "dl":
+				conditional = append(conditional, c)
+			}
+			walk(c)
+		}
+	}
+	walk(top)
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+	// Inner blocks first, so that their removal counts for the outer ones.
+	for _, n := range slices.Backward(conditional) {
+		if isBoilerplate(n) {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// isBoilerplate reports whether a block looks like navigation, a link list or an empty wrapper.
+func isBoilerplate(n *html.Node) bool {
+	text := strings.Join(strings.Fields(NewNode(n).GetText()), " ")
+	if strings.Count(text, ",") >= 10 {
+		return false
+	}
+	var p, img, li, headings int
+	for c := range n.Descendants() {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "p", "pre", "blockquote":
+			p++
+		case "img", "picture", "video", "svg":
+			img++
+		case "li":
+			li++
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			headings++
+		}
+	}
+	weight := classWeight(n)
+	density := linkDensity(n)
+	isList := n.Data == "ul" || n.Data == "ol"
+	switch {
+	case img > 1 && float64(p)/float64(img) < 0.5 && len(text) < 100:
+		return true
+	case !isList && li > p+10:
+		return true
+	case weight < 25 && density > 0.2 && len(text) < 200:
+		return true
+	case density > 0.5:
+		return true
+	case len(text) < 25 && img == 0 && headings == 0 && n.Data != "table":
+		return true
+	}
+	return false
+}
+
+func bylineElement(n *html.Node) bool {
+	attrs := attributes(n.Attr)
+	class, _ := attrs.Get("class")
+	id, _ := attrs.Get("id")
+	rel, _ := attrs.Get("rel")
+	itemprop, _ := attrs.Get("itemprop")
+	if rel != "author" && !strings.Contains(itemprop, "author") && !bylineHints.MatchString(class+" "+id) {
+		return false
+	}
+	text := strings.TrimSpace(NewNode(n).GetText())
+	return text != "" && len(text) < 100
+}
+
+func articleTitle(top *html.Node, meta *PageMetadata) string {
+	if h1 := NewNode(top).Find(Descendant, H1); h1 != nil {
+		if title := strings.Join(strings.Fields(h1.GetText()), " "); title != "" {
+			return title
+		}
+	}
+	if meta.OpenGraph.Title != "" {
+		return meta.OpenGraph.Title
+	}
+	title := meta.Title
+	// Drop the site name, which is usually separated from the title by " | ", " - " and the like.
+	if loc := titleSeparators.FindAllStringIndex(title, -1); len(loc) > 0 {
+		last := loc[len(loc)-1]
+		if before := title[:last[0]]; len(strings.Fields(before)) >= 3 {
+			return before
+		}
+		if after := title[loc[0][1]:]; len(strings.Fields(after)) >= 3 {
+			return after
+		}
+	}
+	return title
+}
+
+func byline(root *html.Node, meta *PageMetadata) string {
+	for c := range root.Descendants() {
+		if c.Type == html.ElementNode && c.Data != "meta" && c.Data != "link" && !skip(c) && bylineElement(c) {
+			text := strings.Join(strings.Fields(NewNode(c).GetText()), " ")
+			if len(text) > 3 && strings.EqualFold(text[:3], "by ") {
+				text = strings.TrimSpace(text[3:])
+			}
+			return text
+		}
+	}
+	return meta.Author
+}
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02", time.RFC1123, time.RFC1123Z}
+
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// published finds the publication time in meta elements, microdata, JSON-LD and <time> elements.
+func published(root, top *html.Node) time.Time {
+	for c := range root.Descendants() {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		attrs := attributes(c.Attr)
+		key, _ := attrs.Get("property")
+		if key == "" {
+			key, _ = attrs.Get("name")
+		}
+		if itemprop, _ := attrs.Get("itemprop"); itemprop == "datePublished" {
+			key = itemprop
+		}
+		switch strings.ToLower(key) {
+		case "article:published_time", "datepublished", "date", "pubdate", "publishdate", "dc.date", "dc.date.issued", "sailthru.date":
+			for _, attr := range []string{"content", "datetime"} {
+				if v, ok := attrs.Get(attr); ok {
+					if t, ok := parseDate(v); ok {
+						return t
+					}
+				}
+			}
+		}
+	}
+	items, _ := JSONLD(NewNode(root))
+	for _, item := range items {
+		if s, ok := item["datePublished"].(string); ok {
+			if t, ok := parseDate(s); ok {
+				return t
+			}
+		}
+	}
+	for _, n := range []*html.Node{top, root} {
+		if t := NewNode(n).Find(Descendant, Tag("time"), Attr("datetime", True)); t != nil {
+			v, _ := t.Attrs().Get("datetime")
+			if t, ok := parseDate(v); ok {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractArticle(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><head>
+<title>Rivers are rising again this spring | Daily News</title>
+<meta property="article:published_time" content="2024-03-05T08:30:00Z">
+</head><body>
+<header class="masthead"><a href="/">Daily News</a><nav><a href="/world">World</a> <a href="/sport">Sport</a></nav></header>
+<div class="layout">
+  <div class="sidebar"><ul><li><a href="/a">Most read story one</a></li><li><a href="/b">Most read story two</a></li></ul>
+  <p>Subscribe to our newsletter, get offers, deals, and more, every single day.</p></div>
+  <article class="post">
+    <h1>Rivers are rising again</h1>
+    <p class="byline">By Jane Doe</p>
+    <p>Heavy rain across the region has pushed rivers to levels not seen in a decade, officials said on Tuesday, warning residents to prepare.</p>
+    <figure class="media"><img src="/flood.jpg" alt="Flooded street"><figcaption>Water on the high street.</figcaption></figure>
+    <div class="share-tools"><a href="/share/fb">Share</a> <a href="/share/x">Post</a></div>
+    <p>Flood barriers were raised in three towns, and volunteers filled sandbags through the night, according to local councils.</p>
+    <script>track()</script>
+    <div class="related"><a href="/c">Related: last year's floods</a></div>
+    <p>Forecasters expect the rain to ease by the weekend, although ground water will keep levels high for days.</p>
+    <div class="wrapper"></div>
+  </article>
+  <div id="comments"><p>Great article, really informative, thanks for writing it, very helpful.</p></div>
+</div>
+<footer><p>Copyright Daily News, all rights reserved, terms, privacy, cookies.</p></footer>
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	article, err := ExtractArticle(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Node.Data() != "article" {
+		t.Errorf("expected article node; got %s", article.Node.Data())
+	}
+	if article.Title != "Rivers are rising again" {
+		t.Errorf("expected title %q; got %q", "Rivers are rising again", article.Title)
+	}
+	if article.Byline != "Jane Doe" {
+		t.Errorf("expected byline %q; got %q", "Jane Doe", article.Byline)
+	}
+	if expected := time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC); !article.Published.Equal(expected) {
+		t.Errorf("expected published %v; got %v", expected, article.Published)
+	}
+	text := article.Node.GetTextWithOptions(TextOptions{Strip: true, BlockAware: true})
+	if n := len(article.Node.FindAll(0, P)); n != 3 {
+		t.Errorf("expected paragraphs %d; got %d:\n%s", 3, n, text)
+	}
+	for _, s := range []string{"Share", "Related", "track", "Jane"} {
+		if strings.Contains(text, s) {
+			t.Errorf("expected %q to be removed; got\n%s", s, text)
+		}
+	}
+	if article.Node.Find(0, Tag("figure")) == nil || !strings.Contains(text, "Water on the high street.") {
+		t.Errorf("expected figure to be kept; got\n%s", text)
+	}
+	if doc.Find(0, nil, Class("wrapper")) != nil {
+		t.Error("expected empty wrapper to be removed")
+	}
+	if doc.Find(0, nil, Id("comments")) == nil {
+		t.Error("expected the tree outside the article to be kept")
+	}
+
+	doc, err = ParseHTMLDocument(`<html><head><title>Empty</title></head><body></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractArticle(doc); err != ErrNoArticle {
+		t.Errorf("expected error %v; got %v", ErrNoArticle, err)
+	}
+}
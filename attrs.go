@@ -1,9 +1,42 @@
 package node
 
+import (
+	"errors"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
 var _ Attributes = attributes{}
 
+// ErrNoAttribute is returned by typed attribute getters when the attribute does not exist.
+var ErrNoAttribute = errors.New("attribute not found")
+
+// AttrError records a failed attempt to read an attribute as a typed value.
+type AttrError struct {
+	Key   string // the attribute key
+	Value string // the attribute value
+	Err   error  // the reason the conversion failed
+}
+
+func (e *AttrError) Error() string {
+	if e.Err == ErrNoAttribute {
+		return "node: attribute " + strconv.Quote(e.Key) + " not found"
+	}
+	return "node: attribute " + strconv.Quote(e.Key) + ": " + e.Err.Error()
+}
+
+func (e *AttrError) Unwrap() error { return e.Err }
+
 // Attributes is an interface that describes a node's attributes with
 // methods for getting and iterating over key-value pairs.
+//
+// Attributes are exposed in source order. When a key appears more than once,
+// the first occurrence wins for Get, Range, All, Keys and Len, while Values and Raw
+// give access to the duplicates.
 type Attributes interface {
 	// Range calls the provided function for each key-value pair in the Attributes
 	// iteration stops if the function returns false for any pair.
@@ -12,16 +45,57 @@ type Attributes interface {
 	// Get returns the value associated with the specified key and
 	// a boolean indicating whether the key exists in the Attributes.
 	Get(key string) (value string, exists bool)
+
+	// All returns an iterator over key-value pairs in source order.
+	All() iter.Seq2[string, string]
+
+	// Len returns the number of distinct keys.
+	Len() int
+
+	// Keys returns the distinct keys in source order.
+	Keys() []string
+
+	// Values returns all values associated with the specified key, including duplicates.
+	Values(key string) []string
+
+	// GetNS returns the value associated with the specified namespace and key,
+	// e.g. GetNS("xlink", "href") for an SVG xlink:href attribute.
+	GetNS(namespace, key string) (value string, exists bool)
+
+	// Raw returns the underlying attributes, including duplicates and namespaces.
+	Raw() []html.Attribute
+
+	// Typed getters, all of them return an *AttrError wrapping ErrNoAttribute if the key does not exist.
+
+	// Int returns the value associated with the specified key as an int.
+	Int(key string) (int, error)
+	// Float returns the value associated with the specified key as a float64.
+	Float(key string) (float64, error)
+	// Bool reports whether the boolean attribute is present, e.g. disabled or checked.
+	Bool(key string) bool
+	// URL returns the value associated with the specified key parsed as a URL.
+	URL(key string) (*url.URL, error)
+	// List returns the value associated with the specified key split into whitespace-separated tokens,
+	// e.g. class or rel.
+	List(key string) []string
+	// Srcset returns the value associated with the specified key parsed as a srcset attribute.
+	// Invalid candidates are skipped and reported in the error, along with the valid ones.
+	Srcset(key string) ([]ImageCandidate, error)
 }
 
-// attributes is a private struct that implements the Attributes interface.
-type attributes map[string]string
+// attributes is a private type that implements the Attributes interface.
+// It is a view over the node's attribute slice, so no copy is made.
+type attributes []html.Attribute
 
 // Range calls the provided function for each key-value pair in the attributes
 // iteration stops if the function returns false for any pair.
+// Duplicate keys after the first are skipped.
 func (attrs attributes) Range(f func(key, value string) bool) {
-	for k, v := range attrs {
-		if !f(k, v) {
+	for i, attr := range attrs {
+		if attrs[:i].has(attr.Key) {
+			continue
+		}
+		if !f(attr.Key, attr.Val) {
 			break
 		}
 	}
@@ -29,7 +103,137 @@ func (attrs attributes) Range(f func(key, value string) bool) {
 
 // Get returns the value associated with the specified key and
 // a boolean indicating whether the key exists in the attributes.
+// If the key is duplicated, the first value wins.
 func (attrs attributes) Get(key string) (value string, exists bool) {
-	v, ok := attrs[key]
-	return v, ok
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// All returns an iterator over key-value pairs in source order.
+// Duplicate keys after the first are skipped.
+func (attrs attributes) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		attrs.Range(yield)
+	}
+}
+
+// Len returns the number of distinct keys.
+func (attrs attributes) Len() (n int) {
+	for range attrs.All() {
+		n++
+	}
+	return
+}
+
+// Keys returns the distinct keys in source order.
+func (attrs attributes) Keys() (keys []string) {
+	for k := range attrs.All() {
+		keys = append(keys, k)
+	}
+	return
+}
+
+// Values returns all values associated with the specified key, including duplicates.
+func (attrs attributes) Values(key string) (values []string) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			values = append(values, attr.Val)
+		}
+	}
+	return
+}
+
+// GetNS returns the value associated with the specified namespace and key.
+func (attrs attributes) GetNS(namespace, key string) (value string, exists bool) {
+	for _, attr := range attrs {
+		if attr.Namespace == namespace && attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// Raw returns the underlying attributes, including duplicates and namespaces.
+func (attrs attributes) Raw() []html.Attribute {
+	return attrs
+}
+
+// Int returns the value associated with the specified key as an int.
+func (attrs attributes) Int(key string) (int, error) {
+	v, err := attrs.value(key)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, &AttrError{key, v, err}
+	}
+	return i, nil
+}
+
+// Float returns the value associated with the specified key as a float64.
+func (attrs attributes) Float(key string) (float64, error) {
+	v, err := attrs.value(key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, &AttrError{key, v, err}
+	}
+	return f, nil
+}
+
+// Bool reports whether the boolean attribute is present.
+func (attrs attributes) Bool(key string) bool {
+	return attrs.has(key)
+}
+
+// URL returns the value associated with the specified key parsed as a URL.
+func (attrs attributes) URL(key string) (*url.URL, error) {
+	v, err := attrs.value(key)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(strings.TrimSpace(v))
+	if err != nil {
+		return nil, &AttrError{key, v, err}
+	}
+	return u, nil
+}
+
+// List returns the value associated with the specified key split into whitespace-separated tokens.
+func (attrs attributes) List(key string) []string {
+	v, _ := attrs.Get(key)
+	return strings.Fields(v)
+}
+
+// Srcset returns the value associated with the specified key parsed as a srcset attribute.
+func (attrs attributes) Srcset(key string) ([]ImageCandidate, error) {
+	v, err := attrs.value(key)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := ParseSrcset(v)
+	if err != nil {
+		return candidates, &AttrError{key, v, err}
+	}
+	return candidates, nil
+}
+
+func (attrs attributes) value(key string) (string, error) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return "", &AttrError{Key: key, Err: ErrNoAttribute}
+	}
+	return v, nil
+}
+
+func (attrs attributes) has(key string) bool {
+	_, ok := attrs.Get(key)
+	return ok
 }
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	doc, err := ParseHTML(`<div id="a" class="x" data-b="1" class="y" title="t" data-b="2"></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs := doc.Find(0, Div).Attrs()
+	if class, _ := attrs.Get("class"); class != "x" {
+		t.Errorf("expected class %q; got %q", "x", class)
+	}
+	expected := []string{"id", "class", "data-b", "title"}
+	if keys := attrs.Keys(); !slices.Equal(keys, expected) {
+		t.Errorf("expected keys %q; got %q", expected, keys)
+	}
+	if l := attrs.Len(); l != 4 {
+		t.Errorf("expected len %d; got %d", 4, l)
+	}
+	var pairs []string
+	for k, v := range attrs.All() {
+		pairs = append(pairs, k+"="+v)
+	}
+	if expected := []string{"id=a", "class=x", "data-b=1", "title=t"}; !slices.Equal(pairs, expected) {
+		t.Errorf("expected pairs %q; got %q", expected, pairs)
+	}
+	if values := attrs.Values("data-b"); !slices.Equal(values, []string{"1", "2"}) {
+		t.Errorf("expected values %q; got %q", []string{"1", "2"}, values)
+	}
+	if l := len(attrs.Raw()); l != 6 {
+		t.Errorf("expected raw %d; got %d", 6, l)
+	}
+
+	doc, err = ParseHTML(`<svg><use xlink:href="#icon"></use></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs = doc.Find(0, Tag("use")).Attrs()
+	if href, ok := attrs.GetNS("xlink", "href"); !ok || href != "#icon" {
+		t.Errorf("expected href %q; got %q", "#icon", href)
+	}
+	if _, ok := attrs.GetNS("", "href"); ok {
+		t.Error("expected no href without namespace")
+	}
+}
+
+func TestTypedAttributes(t *testing.T) {
+	doc, err := ParseHTML(`<img width=" 640 " data-price="12.5" disabled href="/a?b=c" rel="nofollow  noopener" srcset="a.jpg 1x, b.jpg 2x" data-bad="abc">`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs := doc.Find(0, Img).Attrs()
+	if width, err := attrs.Int("width"); err != nil {
+		t.Error(err)
+	} else if width != 640 {
+		t.Errorf("expected width %d; got %d", 640, width)
+	}
+	if price, err := attrs.Float("data-price"); err != nil {
+		t.Error(err)
+	} else if price != 12.5 {
+		t.Errorf("expected price %g; got %g", 12.5, price)
+	}
+	if !attrs.Bool("disabled") {
+		t.Error("expected disabled; got false")
+	}
+	if attrs.Bool("checked") {
+		t.Error("expected not checked; got true")
+	}
+	if u, err := attrs.URL("href"); err != nil {
+		t.Error(err)
+	} else if u.Path != "/a" || u.Query().Get("b") != "c" {
+		t.Errorf("expected url %q; got %q", "/a?b=c", u)
+	}
+	if rel := attrs.List("rel"); !slices.Equal(rel, []string{"nofollow", "noopener"}) {
+		t.Errorf("expected rel %q; got %q", []string{"nofollow", "noopener"}, rel)
+	}
+	if srcset, err := attrs.Srcset("srcset"); err != nil {
+		t.Error(err)
+	} else if len(srcset) != 2 || srcset[1].URL != "b.jpg" || srcset[1].Density != 2 {
+		t.Errorf("unexpected srcset %v", srcset)
+	}
+	if _, err := attrs.Int("data-bad"); err == nil {
+		t.Error("expected error; got nil")
+	} else if e, ok := err.(*AttrError); !ok || e.Key != "data-bad" || e.Value != "abc" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := attrs.Int("height"); !errors.Is(err, ErrNoAttribute) {
+		t.Errorf("expected ErrNoAttribute; got %v", err)
+	}
+}
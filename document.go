@@ -0,0 +1,260 @@
+package node
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Document is the root node of a parsed HTML document together with
+// the metadata collected while parsing it.
+type Document struct {
+	Node
+
+	encoding string
+	url      *url.URL
+	warnings []string
+	source   *sourceMap
+}
+
+// ParseDocument returns the Document for the HTML from the given Reader.
+// The input is assumed to be UTF-8 encoded.
+func ParseDocument(r io.Reader) (*Document, error) {
+	return ParseDocumentWithOptions(r)
+}
+
+// ParseDocumentWithOptions is like ParseDocument, with options.
+func ParseDocumentWithOptions(r io.Reader, opts ...html.ParseOption) (*Document, error) {
+	n, err := ParseWithOptions(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newDocument(n, "utf-8"), nil
+}
+
+// ParseHTMLDocument returns the Document for the HTML from string.
+func ParseHTMLDocument(s string) (*Document, error) {
+	return ParseDocument(strings.NewReader(s))
+}
+
+// ParseDocumentAutoDetect is like ParseAutoDetect but returns a Document,
+// whose Encoding reports the detected encoding.
+func ParseDocumentAutoDetect(r io.Reader, contentType string) (*Document, error) {
+	rd, name, declared, err := newDecodedReader(r, contentType)
+	if err != nil {
+		return nil, err
+	}
+	n, err := Parse(rd)
+	if err != nil {
+		return nil, err
+	}
+	doc := newDocument(n, name)
+	if !declared {
+		doc.warnings = append(doc.warnings, "no character encoding declared, detected "+name)
+	}
+	return doc, nil
+}
+
+// NewDocument returns a Document for the specified root node.
+// The encoding of the document is unknown.
+func NewDocument(root Node) *Document {
+	return newDocument(root, "")
+}
+
+func newDocument(root Node, encoding string) *Document {
+	doc := &Document{Node: root, encoding: encoding}
+	if doc.doctype() == nil {
+		doc.warnings = append(doc.warnings, "missing doctype")
+	}
+	if l := len(doc.FindN(Descendant, 2, Title)); l > 1 {
+		doc.warnings = append(doc.warnings, "multiple <title> elements")
+	}
+	if bases := doc.FindAll(Descendant, Tag("base"), Attr("href", True)); len(bases) > 1 {
+		doc.warnings = append(doc.warnings, "multiple <base> elements with href, using the first one")
+	} else if len(bases) == 1 {
+		href, _ := bases[0].Attrs().Get("href")
+		if _, err := url.Parse(strings.TrimSpace(href)); err != nil {
+			doc.warnings = append(doc.warnings, "invalid <base> href: "+err.Error())
+		}
+	}
+	return doc
+}
+
+// Encoding returns the canonical name of the document's character encoding,
+// or an empty string if it is unknown.
+func (d *Document) Encoding() string {
+	return d.encoding
+}
+
+// Doctype returns the document type declaration, or nil if the document has none.
+func (d *Document) Doctype() DoctypeNode {
+	if n := d.doctype(); n != nil {
+		return NewNode(n).ToDoctypeNode()
+	}
+	return nil
+}
+
+func (d *Document) doctype() *html.Node {
+	for c := d.Raw().FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.DoctypeNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// Quirks reports whether the document would be rendered in quirks mode,
+// as determined by its document type declaration.
+func (d *Document) Quirks() bool {
+	n := d.doctype()
+	if n == nil {
+		return true
+	}
+	return isQuirksDoctype(n)
+}
+
+// Warnings returns the problems noticed while parsing the document.
+func (d *Document) Warnings() []string {
+	return d.warnings
+}
+
+// Title returns the text of the document's first <title> element.
+func (d *Document) Title() string {
+	if title := d.Find(Descendant, Title); title != nil {
+		return strings.Join(strings.Fields(title.GetText()), " ")
+	}
+	return ""
+}
+
+// Head returns the document's <head> element.
+func (d *Document) Head() Node {
+	return d.Find(Descendant, Head)
+}
+
+// Body returns the document's <body> element.
+func (d *Document) Body() Node {
+	return d.Find(Descendant, Body)
+}
+
+// URL returns the URL the document was retrieved from, as set by SetURL.
+func (d *Document) URL() *url.URL {
+	return d.url
+}
+
+// SetURL sets the URL the document was retrieved from.
+// It is used to resolve BaseURL and relative URLs within the document.
+func (d *Document) SetURL(u *url.URL) {
+	d.url = u
+}
+
+// BaseURL returns the document's base URL: the href of the first <base> element
+// resolved against the document URL, or the document URL itself if there is none.
+// It returns nil if neither is known.
+func (d *Document) BaseURL() *url.URL {
+	return baseURL(d.Node, d.url)
+}
+
+// baseURL returns the base URL of the document containing n, resolved against page.
+func baseURL(n HtmlNode, page *url.URL) *url.URL {
+	root := n.Raw()
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	if base := NewNode(root).Find(Descendant, Tag("base"), Attr("href", True)); base != nil {
+		href, _ := base.Attrs().Get("href")
+		if u, err := url.Parse(strings.TrimSpace(href)); err == nil {
+			if page != nil {
+				return page.ResolveReference(u)
+			}
+			return u
+		}
+	}
+	return page
+}
+
+// isQuirksDoctype reports whether the doctype triggers quirks mode
+// according to the HTML specification.
+func isQuirksDoctype(n *html.Node) bool {
+	if n.Data != "html" {
+		return true
+	}
+	public, hasPublic := attributes(n.Attr).Get("public")
+	system, hasSystem := attributes(n.Attr).Get("system")
+	public, system = strings.ToLower(public), strings.ToLower(system)
+	if hasPublic {
+		switch public {
+		case "-//w3o//dtd w3 html strict 3.0//en//", "-/w3d/dtd html 4.0 transitional/en", "html":
+			return true
+		}
+		for _, prefix := range quirkyPublicIDs {
+			if strings.HasPrefix(public, prefix) {
+				return true
+			}
+		}
+		if !hasSystem && (strings.HasPrefix(public, "-//w3c//dtd html 4.01 frameset//") ||
+			strings.HasPrefix(public, "-//w3c//dtd html 4.01 transitional//")) {
+			return true
+		}
+	}
+	return hasSystem && system == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd"
+}
+
+var quirkyPublicIDs = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
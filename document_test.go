@@ -0,0 +1,113 @@
+package node
+
+import (
+	"bytes"
+	"net/url"
+	"slices"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<!DOCTYPE html>
+<html><head><title>  The   Title </title><base href="/docs/"></head><body><p>text</p></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding := doc.Encoding(); encoding != "utf-8" {
+		t.Errorf("expected encoding %q; got %q", "utf-8", encoding)
+	}
+	if doctype := doc.Doctype(); doctype == nil || doctype.Name() != "html" {
+		t.Errorf("expected doctype %q; got %v", "html", doctype)
+	}
+	if doc.Quirks() {
+		t.Error("expected no quirks mode")
+	}
+	if warnings := doc.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings; got %q", warnings)
+	}
+	if title := doc.Title(); title != "The Title" {
+		t.Errorf("expected title %q; got %q", "The Title", title)
+	}
+	if head := doc.Head(); head == nil || head.Data() != "head" {
+		t.Errorf("expected head; got %v", head)
+	}
+	if body := doc.Body(); body == nil || body.GetText() != "text" {
+		t.Errorf("expected body; got %v", body)
+	}
+	if base := doc.BaseURL(); base == nil || base.String() != "/docs/" {
+		t.Errorf("expected base %q; got %v", "/docs/", base)
+	}
+	u, _ := url.Parse("https://example.com/index.html")
+	doc.SetURL(u)
+	if base := doc.BaseURL().String(); base != "https://example.com/docs/" {
+		t.Errorf("expected base %q; got %q", "https://example.com/docs/", base)
+	}
+	if p := doc.Find(0, P); p == nil {
+		t.Error("expected p; got nil")
+	}
+
+	doc, err = ParseHTMLDocument(`<title>a</title><title>b</title>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Quirks() {
+		t.Error("expected quirks mode")
+	}
+	if expected := []string{"missing doctype", "multiple <title> elements"}; !slices.Equal(doc.Warnings(), expected) {
+		t.Errorf("expected warnings %q; got %q", expected, doc.Warnings())
+	}
+	if base := doc.BaseURL(); base != nil {
+		t.Errorf("expected nil base; got %v", base)
+	}
+
+	doc, err = ParseHTMLDocument(`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN"><p>x</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Quirks() {
+		t.Error("expected quirks mode")
+	}
+	if id := doc.Doctype().PublicID(); id != "-//W3C//DTD HTML 4.01 Transitional//EN" {
+		t.Errorf("expected public id %q; got %q", "-//W3C//DTD HTML 4.01 Transitional//EN", id)
+	}
+	doc, err = ParseHTMLDocument(`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd"><p>x</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Quirks() {
+		t.Error("expected no quirks mode")
+	}
+	if id := doc.Doctype().SystemID(); id != "http://www.w3.org/TR/html4/loose.dtd" {
+		t.Errorf("expected system id %q; got %q", "http://www.w3.org/TR/html4/loose.dtd", id)
+	}
+}
+
+func TestParseDocumentAutoDetect(t *testing.T) {
+	b, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(`<!doctype html><meta charset="shift_jis"><title>日本語</title>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseDocumentAutoDetect(bytes.NewReader(b), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding := doc.Encoding(); encoding != "shift_jis" {
+		t.Errorf("expected encoding %q; got %q", "shift_jis", encoding)
+	}
+	if title := doc.Title(); title != "日本語" {
+		t.Errorf("expected title %q; got %q", "日本語", title)
+	}
+	if warnings := doc.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings; got %q", warnings)
+	}
+
+	doc, err = ParseDocumentAutoDetect(bytes.NewReader([]byte("<!doctype html><script charset=\"utf-8\"></script><p>caf\xe9</p>")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"no character encoding declared, detected windows-1252"}; !slices.Equal(doc.Warnings(), expected) {
+		t.Errorf("expected warnings %q; got %q", expected, doc.Warnings())
+	}
+}
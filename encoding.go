@@ -0,0 +1,122 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// ParseWithEncoding returns the parse tree for the HTML from the given Reader,
+// which is transcoded to UTF-8 from the encoding with the specified label,
+// e.g. "shift_jis", "gbk" or "windows-1252".
+func ParseWithEncoding(r io.Reader, label string) (Node, error) {
+	e, _ := charset.Lookup(label)
+	if e == nil {
+		return nil, fmt.Errorf("node: unsupported encoding %q", label)
+	}
+	return Parse(transform.NewReader(r, e.NewDecoder()))
+}
+
+// ParseAutoDetect returns the parse tree for the HTML from the given Reader,
+// transcoded to UTF-8 from its detected encoding, and the canonical name of that encoding.
+//
+// The encoding is determined from, in order of precedence, a byte order mark,
+// the charset parameter of contentType (the value of the Content-Type header, may be empty),
+// a <meta charset> or http-equiv content-type declaration within the first 1024 bytes,
+// and finally by checking whether the content is valid UTF-8, falling back to windows-1252.
+func ParseAutoDetect(r io.Reader, contentType string) (Node, string, error) {
+	rd, name, _, err := newDecodedReader(r, contentType)
+	if err != nil {
+		return nil, "", err
+	}
+	n, err := Parse(rd)
+	if err != nil {
+		return nil, "", err
+	}
+	return n, name, nil
+}
+
+// newDecodedReader reads all of r and returns a reader transcoding it to UTF-8 from its detected encoding,
+// along with the canonical name of the encoding and whether it was declared rather than guessed.
+func newDecodedReader(r io.Reader, contentType string) (io.Reader, string, bool, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", false, err
+	}
+	e, name, certain := detectEncoding(b, contentType)
+	var rd io.Reader = bytes.NewReader(b)
+	if e != encoding.Nop {
+		rd = transform.NewReader(rd, e.NewDecoder())
+	}
+	return rd, name, certain || hasMetaCharset(b), nil
+}
+
+// detectEncoding determines the encoding of content.
+func detectEncoding(content []byte, contentType string) (encoding.Encoding, string, bool) {
+	e, name, certain := charset.DetermineEncoding(content, contentType)
+	// DetermineEncoding only sniffs the first 1024 bytes for UTF-8,
+	// so check the whole content before falling back to windows-1252.
+	if !certain && name == "windows-1252" && !hasMetaCharset(content) && !isASCII(content) && utf8.Valid(content) {
+		return encoding.Nop, "utf-8", false
+	}
+	return e, name, certain
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// hasMetaCharset reports whether a <meta> element within the first 1024 bytes declares a supported
+// charset, with a charset attribute or an http-equiv content-type, like the prescan of DetermineEncoding.
+func hasMetaCharset(content []byte) bool {
+	if len(content) > 1024 {
+		content = content[:1024]
+	}
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" {
+				continue
+			}
+			var label, content string
+			var pragma bool
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					label = string(val)
+				case "content":
+					content = string(val)
+				case "http-equiv":
+					pragma = strings.EqualFold(string(val), "content-type")
+				}
+			}
+			if label == "" && pragma {
+				if _, params, err := mime.ParseMediaType(content); err == nil {
+					label = params["charset"]
+				}
+			}
+			if e, _ := charset.Lookup(label); e != nil {
+				return true
+			}
+		}
+	}
+}
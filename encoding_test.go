@@ -0,0 +1,92 @@
+package node
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func encode(t *testing.T, e encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := e.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseAutoDetect(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		content     []byte
+		contentType string
+		encoding    string
+		text        string
+	}{
+		{
+			"meta charset",
+			encode(t, japanese.ShiftJIS, `<html><head><meta charset="Shift_JIS"></head><body><p>こんにちは</p></body></html>`),
+			"", "shift_jis", "こんにちは",
+		},
+		{
+			"http-equiv",
+			encode(t, simplifiedchinese.GBK, `<meta http-equiv="Content-Type" content="text/html; charset=gbk"><p>你好</p>`),
+			"", "gbk", "你好",
+		},
+		{
+			"content type",
+			encode(t, simplifiedchinese.GBK, `<p>你好</p>`),
+			"text/html; charset=GBK", "gbk", "你好",
+		},
+		{
+			"bom",
+			append([]byte("\xef\xbb\xbf"), `<p>héllo</p>`...),
+			"", "utf-8", "héllo",
+		},
+		{
+			"fallback",
+			encode(t, charmap.Windows1252, `<p>café</p>`),
+			"", "windows-1252", "café",
+		},
+		{
+			"late utf-8",
+			[]byte(`<p>` + strings.Repeat(" ", 2048) + `café</p>`),
+			"", "utf-8", strings.Repeat(" ", 2048) + "café",
+		},
+		{
+			"late utf-8 with script charset",
+			[]byte(`<script charset="utf-8" src="app.js"></script><p>` + strings.Repeat(" ", 2048) + `café</p>`),
+			"", "utf-8", strings.Repeat(" ", 2048) + "café",
+		},
+	} {
+		doc, name, err := ParseAutoDetect(bytes.NewReader(tc.content), tc.contentType)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if name != tc.encoding {
+			t.Errorf("%s: expected encoding %q; got %q", tc.name, tc.encoding, name)
+		}
+		if text := doc.Find(0, P).GetText(); text != tc.text {
+			t.Errorf("%s: expected text %q; got %q", tc.name, tc.text, text)
+		}
+	}
+}
+
+func TestParseWithEncoding(t *testing.T) {
+	doc, err := ParseWithEncoding(bytes.NewReader(encode(t, japanese.ShiftJIS, `<p>日本語</p>`)), "sjis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := doc.Find(0, P).GetText(); text != "日本語" {
+		t.Errorf("expected text %q; got %q", "日本語", text)
+	}
+	if _, err := ParseWithEncoding(strings.NewReader(""), "no-such-encoding"); err == nil {
+		t.Error("expected error; got nil")
+	}
+}
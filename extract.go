@@ -0,0 +1,315 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Rule describes how to extract a value from the parse tree.
+// A rule document is a map of field names to rules, as read by LoadJSONRules and LoadYAMLRules.
+type Rule struct {
+	// At most one of CSS, XPath or a Find-style combination of Tag, Class and ID selects the nodes.
+	// Without a selector the value is taken from the current node.
+	CSS   string `json:"css,omitempty" yaml:"css,omitempty"`
+	XPath string `json:"xpath,omitempty" yaml:"xpath,omitempty"`
+	Tag   string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Class string `json:"class,omitempty" yaml:"class,omitempty"`
+	ID    string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Attr takes the value of an attribute instead of the text, skipping nodes without it.
+	Attr string `json:"attr,omitempty" yaml:"attr,omitempty"`
+	// HTML takes the inner HTML instead of the text.
+	HTML bool `json:"html,omitempty" yaml:"html,omitempty"`
+
+	// Process lists the steps applied to each value in order:
+	//
+	//	trim          remove surrounding whitespace
+	//	collapse      collapse runs of whitespace and trim
+	//	lower, upper  change the case
+	//	regex=EXPR    keep the first submatch of EXPR, or the whole match without groups,
+	//	              or an empty string if it does not match
+	//	number        parse the first number, ignoring thousands separators, as a float64
+	//	int           parse the first integer as an int
+	//
+	// number and int must be the last step.
+	Process []string `json:"process,omitempty" yaml:"process,omitempty"`
+
+	// List extracts a slice of every matched node instead of the first one.
+	List bool `json:"list,omitempty" yaml:"list,omitempty"`
+	// Required reports an error if nothing is matched instead of extracting nil.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Fields extracts a map for each matched node, using it as the current node.
+	Fields map[string]*Rule `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// RuleError describes a rule which is invalid or failed to extract its value.
+type RuleError struct {
+	Rule string // path of the rule, e.g. "items.price"
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("node: rule %s: %v", e.Rule, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Extractor extracts values from the parse tree according to validated rules.
+type Extractor struct {
+	fields []*compiledRule
+}
+
+type compiledRule struct {
+	*Rule
+	name    string // field name
+	path    string // for errors
+	sel     Selector
+	steps   []func(string) string
+	convert func(string) (any, error)
+	fields  []*compiledRule
+}
+
+// NewExtractor validates the rules, compiling their selectors and processing steps.
+func NewExtractor(rules map[string]*Rule) (*Extractor, error) {
+	fields, err := compileRules(rules, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Extractor{fields}, nil
+}
+
+// LoadJSONRules reads a rule document in JSON and returns its Extractor. Unknown keys are rejected.
+func LoadJSONRules(r io.Reader) (*Extractor, error) {
+	var rules map[string]*Rule
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("node: invalid rules: %w", err)
+	}
+	return NewExtractor(rules)
+}
+
+// LoadYAMLRules reads a rule document in YAML and returns its Extractor. Unknown keys are rejected.
+func LoadYAMLRules(r io.Reader) (*Extractor, error) {
+	var rules map[string]*Rule
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	if err := d.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("node: invalid rules: %w", err)
+	}
+	return NewExtractor(rules)
+}
+
+func compileRules(rules map[string]*Rule, path string) ([]*compiledRule, error) {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var fields []*compiledRule
+	for _, name := range names {
+		rule := rules[name]
+		if rule == nil {
+			rule = new(Rule)
+		}
+		c, err := compileRule(rule, fieldPath(path, name))
+		if err != nil {
+			return nil, err
+		}
+		c.name = name
+		fields = append(fields, c)
+	}
+	return fields, nil
+}
+
+func compileRule(rule *Rule, path string) (c *compiledRule, err error) {
+	defer func() {
+		if _, ok := err.(*RuleError); err != nil && !ok {
+			err = &RuleError{path, err}
+		}
+	}()
+	c = &compiledRule{Rule: rule, path: path}
+	hasFind := rule.Tag != "" || rule.Class != "" || rule.ID != ""
+	switch {
+	case (rule.CSS != "") && (rule.XPath != "" || hasFind), rule.XPath != "" && hasFind:
+		return nil, errors.New("multiple selectors")
+	case rule.CSS != "":
+		if c.sel, err = CompileCSS(rule.CSS); err != nil {
+			return nil, fmt.Errorf("invalid css %q: %w", rule.CSS, err)
+		}
+	case rule.XPath != "":
+		if c.sel, err = CompileXPath(rule.XPath); err != nil {
+			return nil, fmt.Errorf("invalid xpath %q: %w", rule.XPath, err)
+		}
+	case hasFind:
+		var tag TagFilter
+		var filters []Filter
+		if rule.Tag != "" {
+			tag = Tag(rule.Tag)
+		}
+		if rule.Class != "" {
+			filters = append(filters, Class(rule.Class))
+		}
+		if rule.ID != "" {
+			filters = append(filters, Id(rule.ID))
+		}
+		c.sel = FindSelector(tag, filters...)
+	}
+	if rule.Attr != "" && rule.HTML {
+		return nil, errors.New("both html and attr")
+	}
+	if rule.Fields != nil {
+		if rule.Attr != "" || rule.HTML || len(rule.Process) > 0 {
+			return nil, errors.New("fields cannot be combined with attr, html or process")
+		}
+		if c.fields, err = compileRules(rule.Fields, path); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	for i, step := range rule.Process {
+		if c.convert != nil {
+			return nil, fmt.Errorf("step %q after %q", step, rule.Process[i-1])
+		}
+		name, arg, _ := strings.Cut(step, "=")
+		switch name {
+		case "trim":
+			c.steps = append(c.steps, strings.TrimSpace)
+		case "collapse":
+			c.steps = append(c.steps, func(s string) string { return strings.TrimSpace(collapseWhitespace(s)) })
+		case "lower":
+			c.steps = append(c.steps, strings.ToLower)
+		case "upper":
+			c.steps = append(c.steps, strings.ToUpper)
+		case "regex":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex %q: %w", arg, err)
+			}
+			c.steps = append(c.steps, func(s string) string {
+				switch m := re.FindStringSubmatch(s); len(m) {
+				case 0:
+					return ""
+				case 1:
+					return m[0]
+				default:
+					return m[1]
+				}
+			})
+		case "number":
+			c.convert = parseNumber
+		case "int":
+			c.convert = parseInt
+		default:
+			return nil, fmt.Errorf("unknown process step %q", step)
+		}
+	}
+	return c, nil
+}
+
+var (
+	numberPattern = regexp.MustCompile(`[-+]?(?:\d[\d,]*(?:\.\d*)?|\.\d+)(?:[eE][-+]?\d+)?`)
+	intPattern    = regexp.MustCompile(`[-+]?\d[\d,]*`)
+)
+
+func parseNumber(s string) (any, error) {
+	m := numberPattern.FindString(s)
+	if m == "" {
+		return nil, fmt.Errorf("no number in %q", s)
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(m, ",", ""), 64)
+}
+
+func parseInt(s string) (any, error) {
+	m := intPattern.FindString(s)
+	if m == "" {
+		return nil, fmt.Errorf("no integer in %q", s)
+	}
+	return strconv.Atoi(strings.ReplaceAll(m, ",", ""))
+}
+
+// Extract runs the rules against n. Each field holds a string, a number from the number or int step,
+// a map[string]any for rules with fields, a []any for list rules, or nil if nothing was matched.
+func (e *Extractor) Extract(n HtmlNode) (map[string]any, error) {
+	return extractFields(n, e.fields)
+}
+
+func extractFields(n HtmlNode, fields []*compiledRule) (map[string]any, error) {
+	m := make(map[string]any, len(fields))
+	for _, c := range fields {
+		v, err := c.extract(n)
+		if err != nil {
+			return nil, err
+		}
+		m[c.name] = v
+	}
+	return m, nil
+}
+
+func (c *compiledRule) extract(n HtmlNode) (any, error) {
+	nodes := []Node{n.ToNode()}
+	if c.sel != nil {
+		nodes = c.sel.SelectAll(n)
+	}
+	if c.Attr != "" {
+		nodes = slices.DeleteFunc(nodes, func(n Node) bool { return !n.HasAttr(c.Attr) })
+	}
+	if len(nodes) == 0 {
+		if c.Required {
+			return nil, &RuleError{c.path, ErrNoMatch}
+		}
+		if c.List {
+			return []any{}, nil
+		}
+		return nil, nil
+	}
+	if !c.List {
+		return c.value(nodes[0])
+	}
+	values := make([]any, len(nodes))
+	for i, n := range nodes {
+		v, err := c.value(n)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (c *compiledRule) value(n Node) (any, error) {
+	if c.fields != nil {
+		return extractFields(n, c.fields)
+	}
+	var s string
+	switch {
+	case c.Attr != "":
+		s, _ = n.Attrs().Get(c.Attr)
+	case c.HTML:
+		s = innerHTML(n.Raw())
+	default:
+		s = strings.TrimSpace(n.GetText())
+	}
+	for _, step := range c.steps {
+		s = step(s)
+	}
+	if c.convert == nil {
+		return s, nil
+	}
+	v, err := c.convert(s)
+	if err != nil {
+		return nil, &RuleError{c.path, err}
+	}
+	return v, nil
+}
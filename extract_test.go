@@ -0,0 +1,99 @@
+package node
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractor(t *testing.T) {
+	doc, err := ParseHTML(`<h1>  Fruit   shop </h1>
+<div class="item"><h2>Apple</h2><span class="price">$1,299.50</span><a href="/apple">more</a><span class="tag">Red</span></div>
+<div class="item"><h2>Kiwi</h2><span class="price">from $2</span><span class="tag">Green</span><span class="tag">Fuzzy</span></div>
+<p id="count">Showing 2 of 10 items</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"title": "Fruit shop",
+		"total": 10,
+		"items": []any{
+			map[string]any{"name": "Apple", "price": 1299.5, "link": "/apple", "tags": []any{"red"}},
+			map[string]any{"name": "Kiwi", "price": 2.0, "link": nil, "tags": []any{"green", "fuzzy"}},
+		},
+	}
+
+	yamlRules := `
+title:
+  xpath: //h1
+  process: [collapse]
+total:
+  id: count
+  process: ["regex=of (\\d+)", int]
+items:
+  css: .item
+  list: true
+  fields:
+    name: {tag: h2}
+    price: {class: price, process: [number]}
+    link: {css: a, attr: href}
+    tags: {css: .tag, list: true, process: [lower]}
+`
+	jsonRules := `{
+	"title": {"xpath": "//h1", "process": ["collapse"]},
+	"total": {"id": "count", "process": ["regex=of (\\d+)", "int"]},
+	"items": {"css": ".item", "list": true, "fields": {
+		"name": {"tag": "h2"},
+		"price": {"class": "price", "process": ["number"]},
+		"link": {"css": "a", "attr": "href"},
+		"tags": {"css": ".tag", "list": true, "process": ["lower"]}
+	}}
+}`
+	for name, load := range map[string]func() (*Extractor, error){
+		"yaml": func() (*Extractor, error) { return LoadYAMLRules(strings.NewReader(yamlRules)) },
+		"json": func() (*Extractor, error) { return LoadJSONRules(strings.NewReader(jsonRules)) },
+	} {
+		e, err := load()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		m, err := e.Extract(doc)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(m, expected) {
+			t.Errorf("%s: expected %v; got %v", name, expected, m)
+		}
+	}
+
+	for rules, expected := range map[string]string{
+		`{"a": {"css": "[", "list": true}}`:              "node: rule a: invalid css",
+		`{"a": {"fields": {"b": {"xpath": "//["}}}}`:     "node: rule a.b: invalid xpath",
+		`{"a": {"css": "p", "tag": "p"}}`:                "node: rule a: multiple selectors",
+		`{"a": {"process": ["number", "trim"]}}`:         `node: rule a: step "trim" after "number"`,
+		`{"a": {"process": ["reverse"]}}`:                `node: rule a: unknown process step "reverse"`,
+		`{"a": {"selector": "p"}}`:                       "node: invalid rules",
+		`{"a": {"css": "p", "fields": {}, "attr": "x"}}`: "node: rule a: fields cannot be combined",
+		`{"a": {"css": "p", "process": ["regex=("]}}`:    "node: rule a: invalid regex",
+	} {
+		if _, err := LoadJSONRules(strings.NewReader(rules)); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("%s: expected error %q; got %v", rules, expected, err)
+		}
+	}
+
+	e, err := NewExtractor(map[string]*Rule{"price": {CSS: ".price", List: true, Process: []string{"int"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Extract(doc.Find(0, H1)); err != nil {
+		t.Errorf("expected no error for empty list; got %v", err)
+	}
+	e, err = NewExtractor(map[string]*Rule{"missing": {CSS: ".missing", Required: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Extract(doc); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected error %v; got %v", ErrNoMatch, err)
+	}
+}
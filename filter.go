@@ -103,13 +103,9 @@ func (attribute attribute[T]) IsMatch(node Node) bool {
 
 // getAttribute returns the value of the specified attribute of the given node.
 // It returns the attribute value and true if the attribute exists, empty string and false otherwise.
+// The node's attribute slice is scanned directly so that no allocation is made.
 func getAttribute(node HtmlNode, name string) (string, bool) {
-	if attr := node.Attrs(); attr == nil {
-		return "", false
-	} else {
-		attr, ok := attr.Get(name)
-		return attr, ok
-	}
+	return attributes(node.Raw().Attr).Get(name)
 }
 
 // isAttributeFilter checks if a list of filters only contains attribute filters.
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -96,3 +97,30 @@ func TestFilter(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkFindAllAttr(b *testing.B) {
+	var sb strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&sb, `<div class="item" data-id="%d" data-kind="k%d"><a href="/p/%d" title="t">%d</a></div>`, i, i%10, i, i)
+	}
+	doc, err := ParseHTML(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		doc.FindAll(0, nil, Attr("data-kind", "k3"), Attr("data-id", True), Class("item"))
+	}
+}
+
+func BenchmarkHasAttr(b *testing.B) {
+	doc, err := ParseHTML(`<a href="/" class="x" id="y" title="z" rel="nofollow">link</a>`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	a := doc.Find(0, A)
+	b.ReportAllocs()
+	for b.Loop() {
+		a.HasAttr("rel")
+	}
+}
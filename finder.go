@@ -29,6 +29,15 @@ type Finder interface {
 	// FindAllString searches for all text nodes in the parse tree based on the specified find method and filters.
 	FindAllString(FindMethod, StringFilter) []TextNode
 
+	// FindComment searches for the first matched comment node in the parse tree based on the specified find method and filters.
+	FindComment(FindMethod, StringFilter) CommentNode
+
+	// FindCommentN searches for up to n comment nodes in the parse tree based on the specified find method and filters.
+	FindCommentN(FindMethod, int, StringFilter) []CommentNode
+
+	// FindAllComment searches for all comment nodes in the parse tree based on the specified find method and filters.
+	FindAllComment(FindMethod, StringFilter) []CommentNode
+
 	// CSS selectors support
 
 	// Select searches for the first matched node in the parse tree based on the css selector.
@@ -75,22 +84,28 @@ const (
 	Next
 )
 
-func findTextNode(tag TagFilter, filters []Filter, strict bool) bool {
-	if strict || ((tag == nil || tag.Ignore()) && !isAttributeFilter(filters)) {
-		return true
+// findNodeType returns the type of nodes to find. Searching for elements without a tag filter
+// and with non-attribute filters finds text nodes instead.
+func findNodeType(tag TagFilter, filters []Filter, typ html.NodeType) html.NodeType {
+	if typ == html.ElementNode && (tag == nil || tag.Ignore()) && !isAttributeFilter(filters) {
+		return html.TextNode
 	}
-	return false
+	return typ
 }
 
-func isMatchType(node Node, findTextNode bool) bool {
-	if t := node.Type(); findTextNode {
-		return t == html.TextNode
-	} else {
-		return t == html.ElementNode
-	}
+func isMatchType(node Node, typ html.NodeType) bool {
+	return node.Type() == typ
 }
 
-func (n *htmlNode) find(method FindMethod, text bool, limit int, tag TagFilter, filters ...Filter) (nodes []Node) {
+func (n *htmlNode) find(method FindMethod, typ html.NodeType, limit int, tag TagFilter, filters ...Filter) (nodes []Node) {
+	typ = findNodeType(tag, filters, typ)
+	if method == Descendant && typ == html.ElementNode {
+		if idx := lookupIndex(n.Node); idx != nil {
+			if candidates, ok := idx.candidates(tag, filters); ok {
+				return n.findIndexed(candidates, false, limit, tag, filters...)
+			}
+		}
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	var f func(Node, ...Filter)
@@ -99,7 +114,7 @@ func (n *htmlNode) find(method FindMethod, text bool, limit int, tag TagFilter,
 			return
 		}
 		if raw := node.Raw(); n.Raw() != raw &&
-			isMatchType(node, findTextNode(tag, filters, text)) &&
+			isMatchType(node, typ) &&
 			(tag == nil || tag.IsMatch(node)) {
 			ok := true
 			for _, i := range filters {
@@ -152,54 +167,75 @@ func (n *htmlNode) find(method FindMethod, text bool, limit int, tag TagFilter,
 	return
 }
 
-func (n *htmlNode) findOnce(method FindMethod, text bool, tag TagFilter, filters ...Filter) Node {
-	nodes := n.find(method, text, 1, tag, filters...)
+func (n *htmlNode) findOnce(method FindMethod, typ html.NodeType, tag TagFilter, filters ...Filter) Node {
+	nodes := n.find(method, typ, 1, tag, filters...)
 	if len(nodes) == 0 {
 		return nil
 	}
 	return nodes[0]
 }
 
-func (n *htmlNode) findN(method FindMethod, text bool, limit int, tag TagFilter, filters ...Filter) []Node {
+func (n *htmlNode) findN(method FindMethod, typ html.NodeType, limit int, tag TagFilter, filters ...Filter) []Node {
 	if limit <= 0 {
 		return nil
 	}
-	return n.find(method, text, limit, tag, filters...)
+	return n.find(method, typ, limit, tag, filters...)
 }
 
 func (n *htmlNode) Find(method FindMethod, tag TagFilter, filters ...Filter) Node {
-	return n.findOnce(method, false, tag, filters...)
+	return n.findOnce(method, html.ElementNode, tag, filters...)
 }
 
 func (n *htmlNode) FindN(method FindMethod, limit int, tag TagFilter, filters ...Filter) []Node {
-	return n.findN(method, false, limit, tag, filters...)
+	return n.findN(method, html.ElementNode, limit, tag, filters...)
 }
 
 func (n *htmlNode) FindAll(method FindMethod, tag TagFilter, filters ...Filter) []Node {
-	return n.find(method, false, 0, tag, filters...)
+	return n.find(method, html.ElementNode, 0, tag, filters...)
 }
 
 func (n *htmlNode) FindString(method FindMethod, filter StringFilter) TextNode {
-	if node := n.findOnce(method, true, nil, filter); node != nil {
+	if node := n.findOnce(method, html.TextNode, nil, filter); node != nil {
 		return node.ToTextNode()
 	}
 	return nil
 }
 
 func (n *htmlNode) FindStringN(method FindMethod, limit int, filter StringFilter) (res []TextNode) {
-	for _, i := range n.findN(method, true, limit, nil, filter) {
+	for _, i := range n.findN(method, html.TextNode, limit, nil, filter) {
 		res = append(res, i.ToTextNode())
 	}
 	return
 }
 
 func (n *htmlNode) FindAllString(method FindMethod, filter StringFilter) (res []TextNode) {
-	for _, i := range n.find(method, true, 0, nil, filter) {
+	for _, i := range n.find(method, html.TextNode, 0, nil, filter) {
 		res = append(res, i.ToTextNode())
 	}
 	return
 }
 
+func (n *htmlNode) FindComment(method FindMethod, filter StringFilter) CommentNode {
+	if node := n.findOnce(method, html.CommentNode, nil, filter); node != nil {
+		return node.ToCommentNode()
+	}
+	return nil
+}
+
+func (n *htmlNode) FindCommentN(method FindMethod, limit int, filter StringFilter) (res []CommentNode) {
+	for _, i := range n.findN(method, html.CommentNode, limit, nil, filter) {
+		res = append(res, i.ToCommentNode())
+	}
+	return
+}
+
+func (n *htmlNode) FindAllComment(method FindMethod, filter StringFilter) (res []CommentNode) {
+	for _, i := range n.find(method, html.CommentNode, 0, nil, filter) {
+		res = append(res, i.ToCommentNode())
+	}
+	return
+}
+
 func (n *htmlNode) Select(sel string) Node {
 	nodes := n.SelectAll(sel)
 	if len(nodes) == 0 {
@@ -209,6 +245,9 @@ func (n *htmlNode) Select(sel string) Node {
 }
 
 func (n *htmlNode) SelectAll(sel string) (res []Node) {
+	if nodes, ok := n.selectIndexed(sel); ok {
+		return nodes
+	}
 	for _, i := range css.MustParse(sel).Select(n.Raw()) {
 		res = append(res, NewNode(i))
 	}
@@ -348,3 +348,35 @@ func TestEvaluate(t *testing.T) {
 		t.Error("expected false; got true")
 	}
 }
+
+func TestFindComment(t *testing.T) {
+	doc, err := ParseHTML(`<html><head><!--[if IE]><p>old</p><![endif]--></head>
+<body><!-- data: {"id": 1} --><div><!-- inner --><p>text</p></div><![CDATA[raw]]></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes := doc.FindAllComment(0, True); len(nodes) != 4 {
+		t.Errorf("expected comments %d; got %d", 4, len(nodes))
+	}
+	if node := doc.FindComment(0, String(regexp.MustCompile(`^\s*data:`))); node == nil {
+		t.Error("expected comment; got nil")
+	} else if s := node.String(); s != ` data: {"id": 1} ` {
+		t.Errorf("expected comment %q; got %q", ` data: {"id": 1} `, s)
+	}
+	if nodes := doc.Find(0, Div).FindCommentN(0, 5, True); len(nodes) != 1 {
+		t.Errorf("expected comments %d; got %d", 1, len(nodes))
+	} else if s := nodes[0].String(); s != " inner " {
+		t.Errorf("expected comment %q; got %q", " inner ", s)
+	}
+	if node := doc.Find(0, P).FindComment(Previous, String(" inner ")); node == nil {
+		t.Error("expected comment; got nil")
+	}
+	if node := doc.FindComment(0, String(regexp.MustCompile("CDATA"))); node == nil {
+		t.Error("expected comment; got nil")
+	} else if s, ok := node.CDATA(); !ok || s != "raw" {
+		t.Errorf("expected cdata %q; got %q", "raw", s)
+	}
+	if node := doc.FindComment(0, String("nosuchcomment")); node != nil {
+		t.Errorf("expected nil; got %q", node.String())
+	}
+}
@@ -0,0 +1,547 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNotForm is returned by ParseForm when the node is not a <form> element.
+var ErrNotForm = errors.New("node is not a form element")
+
+// HtmlForm is a model of a form and the state of its controls.
+// Setting values changes the model only, the parse tree is left untouched.
+type HtmlForm struct {
+	Node Node
+	// Action is the resolved URL the form is submitted to, or nil if it is unknown.
+	Action *url.URL
+	// Method is "GET", "POST" or "DIALOG".
+	Method string
+	// Enctype is the encoding of POST submissions: "application/x-www-form-urlencoded",
+	// "multipart/form-data" or "text/plain".
+	Enctype string
+	// Controls are the form-associated controls in tree order, including those outside
+	// the form which refer to it with a form attribute.
+	Controls []*FormControl
+
+	base, page *url.URL
+}
+
+// FormControl is an input, select, textarea or button element of a form.
+type FormControl struct {
+	Node Node
+	Name string
+	// Type is the type of an input or button, e.g. "text", "checkbox" or "submit",
+	// and "select", "select-multiple" or "textarea" for the other controls.
+	Type string
+	// Value is the current value. For checkboxes and radio buttons it is the value submitted when checked.
+	Value string
+	// Checked is the checkedness of checkboxes and radio buttons.
+	Checked bool
+	// Disabled reports whether the control, or a fieldset containing it, is disabled.
+	Disabled bool
+	// Options are the options of a select element.
+	Options []*FormOption
+	// File holds the file selected with SetFile for a file input.
+	File *FormFile
+}
+
+// FormOption is an option of a select element.
+type FormOption struct {
+	Value    string
+	Label    string
+	Selected bool
+	Disabled bool
+}
+
+// FormFile is a file to be submitted with a file input.
+type FormFile struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// FormEntry is an entry of the form data set.
+type FormEntry struct {
+	Name  string
+	Value string
+	File  *FormFile // for file inputs, with an empty filename if no file was selected
+}
+
+// ParseForm reads the form element n and the default state of its controls.
+// The action is resolved against the document's base URL and the page URL, which may be nil.
+// An empty or missing action is the page URL itself.
+func ParseForm(n Node, page *url.URL) (*HtmlForm, error) {
+	if n == nil || n.Type() != html.ElementNode || n.Data() != "form" {
+		return nil, ErrNotForm
+	}
+	f := &HtmlForm{Node: n, base: baseURL(n, page), page: page}
+	f.Action, f.Method, f.Enctype = f.submission(nil)
+
+	root := n.Raw()
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	id, _ := n.Attrs().Get("id")
+	for c := range root.Descendants() {
+		if c.Type != html.ElementNode || c.Namespace != "" {
+			continue
+		}
+		switch c.Data {
+		case "input", "select", "textarea", "button":
+		default:
+			continue
+		}
+		if owner, ok := attributes(c.Attr).Get("form"); ok {
+			if id == "" || owner != id {
+				continue
+			}
+		} else if formOwner(c) != n.Raw() {
+			continue
+		}
+		f.Controls = append(f.Controls, newFormControl(c))
+	}
+	// Checking a radio button unchecks the others of its group, so the last checked one wins.
+	checked := make(map[string]*FormControl)
+	for _, c := range f.Controls {
+		if c.Type == "radio" && c.Checked && c.Name != "" {
+			if prev := checked[c.Name]; prev != nil {
+				prev.Checked = false
+			}
+			checked[c.Name] = c
+		}
+	}
+	return f, nil
+}
+
+// formOwner returns the nearest ancestor form of n.
+func formOwner(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "form" {
+			return p
+		}
+	}
+	return nil
+}
+
+func newFormControl(n *html.Node) *FormControl {
+	attrs := attributes(n.Attr)
+	c := &FormControl{Node: NewNode(n), Disabled: isDisabled(n)}
+	c.Name, _ = attrs.Get("name")
+	switch n.Data {
+	case "input":
+		typ, _ := attrs.Get("type")
+		c.Type = strings.ToLower(strings.TrimSpace(typ))
+		switch c.Type {
+		case "hidden", "text", "search", "tel", "url", "email", "password", "date", "month", "week", "time",
+			"datetime-local", "number", "range", "color", "checkbox", "radio", "file", "submit", "image", "reset", "button":
+		default:
+			c.Type = "text"
+		}
+		var ok bool
+		if c.Value, ok = attrs.Get("value"); !ok && (c.Type == "checkbox" || c.Type == "radio") {
+			c.Value = "on"
+		}
+		c.Checked = attrs.has("checked")
+	case "button":
+		typ, _ := attrs.Get("type")
+		c.Type = strings.ToLower(strings.TrimSpace(typ))
+		if c.Type != "reset" && c.Type != "button" {
+			c.Type = "submit"
+		}
+		c.Value, _ = attrs.Get("value")
+	case "textarea":
+		c.Type = "textarea"
+		c.Value = NewNode(n).GetText()
+	case "select":
+		c.Type = "select"
+		if attrs.has("multiple") {
+			c.Type = "select-multiple"
+		}
+		for o := range n.Descendants() {
+			if o.Type != html.ElementNode || o.Data != "option" {
+				continue
+			}
+			oa := attributes(o.Attr)
+			label := strings.Join(strings.Fields(NewNode(o).GetText()), " ")
+			option := &FormOption{Label: label, Selected: oa.has("selected")}
+			var ok bool
+			if option.Value, ok = oa.Get("value"); !ok {
+				option.Value = label
+			}
+			option.Disabled = oa.has("disabled") || (o.Parent.Data == "optgroup" && attributes(o.Parent.Attr).has("disabled"))
+			c.Options = append(c.Options, option)
+		}
+		c.resetSelectedness()
+	}
+	return c
+}
+
+// isDisabled reports whether a control is disabled by its attribute or by a fieldset,
+// unless it is inside the first legend of that fieldset.
+func isDisabled(n *html.Node) bool {
+	if attributes(n.Attr).has("disabled") {
+		return true
+	}
+	child := n
+	for p := n.Parent; p != nil; child, p = p, p.Parent {
+		if p.Type != html.ElementNode || p.Data != "fieldset" || !attributes(p.Attr).has("disabled") {
+			continue
+		}
+		var legend *html.Node
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "legend" {
+				legend = c
+				break
+			}
+		}
+		if legend == nil || child != legend {
+			return true
+		}
+	}
+	return false
+}
+
+// resetSelectedness keeps only the last selected option of a single select, and selects its first
+// enabled option if none is selected and it is displayed as a drop-down box, as browsers do.
+func (c *FormControl) resetSelectedness() {
+	if c.Type != "select" {
+		return
+	}
+	selected := -1
+	for i, o := range c.Options {
+		if o.Selected {
+			selected = i
+		}
+	}
+	if size, err := attributes(c.Node.Raw().Attr).Int("size"); selected < 0 && (err != nil || size <= 1) {
+		selected = slices.IndexFunc(c.Options, func(o *FormOption) bool { return !o.Disabled })
+	}
+	for i, o := range c.Options {
+		o.Selected = i == selected
+	}
+}
+
+// Control returns the first control with the name, or nil if there is none.
+func (f *HtmlForm) Control(name string) *FormControl {
+	for _, c := range f.Controls {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Set sets the value of the controls with the name, other than buttons. Text controls and textareas take
+// the values in tree order, one each, and are emptied when there are fewer values than controls.
+// Checkboxes and radio buttons are checked if their value is given and unchecked otherwise,
+// and the options of a select are selected by value. Setting a value no option or checkbox has,
+// several values of a single select or a radio group, or more values than text controls, is an error.
+func (f *HtmlForm) Set(name string, values ...string) error {
+	var controls []*FormControl
+	for _, c := range f.Controls {
+		if c.Name == name && c.Type != "submit" && c.Type != "image" && c.Type != "reset" && c.Type != "button" {
+			controls = append(controls, c)
+		}
+	}
+	if len(controls) == 0 {
+		return fmt.Errorf("node: no control named %q", name)
+	}
+	// Validate all values before changing any control.
+	remaining := slices.Clone(values)
+	var texts int
+	for _, c := range controls {
+		switch c.Type {
+		case "file":
+			return fmt.Errorf("node: use SetFile for file control %q", name)
+		case "checkbox", "radio":
+			if c.Type == "radio" && len(values) > 1 {
+				return fmt.Errorf("node: multiple values for radio group %q", name)
+			}
+			remaining = slices.DeleteFunc(remaining, func(v string) bool { return v == c.Value })
+		case "select", "select-multiple":
+			if c.Type == "select" && len(values) > 1 {
+				return fmt.Errorf("node: multiple values for select %q", name)
+			}
+			for _, o := range c.Options {
+				remaining = slices.DeleteFunc(remaining, func(v string) bool { return v == o.Value })
+			}
+		default:
+			texts++
+		}
+	}
+	if texts > 0 {
+		if len(values) > texts {
+			return fmt.Errorf("node: %d values for %d text controls named %q", len(values), texts, name)
+		}
+		remaining = nil
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("node: invalid value %q for %q", remaining[0], name)
+	}
+	var radioChecked bool
+	var text int
+	for _, c := range controls {
+		switch c.Type {
+		case "checkbox":
+			c.Checked = slices.Contains(values, c.Value)
+		case "radio":
+			// Only one radio button of a group can be checked, even if several share the value.
+			c.Checked = !radioChecked && slices.Contains(values, c.Value)
+			radioChecked = radioChecked || c.Checked
+		case "select", "select-multiple":
+			for _, o := range c.Options {
+				o.Selected = slices.Contains(values, o.Value)
+			}
+		default:
+			c.Value = ""
+			if text < len(values) {
+				c.Value = values[text]
+			}
+			text++
+		}
+	}
+	return nil
+}
+
+// SetFile selects the file submitted with the file control of the name.
+func (f *HtmlForm) SetFile(name string, file *FormFile) error {
+	for _, c := range f.Controls {
+		if c.Name == name && c.Type == "file" {
+			c.File = file
+			return nil
+		}
+	}
+	return fmt.Errorf("node: no file control named %q", name)
+}
+
+// Entries returns the form data set constructed from the current state of the controls,
+// as specified by HTML. Disabled controls, unchecked checkboxes and radio buttons,
+// controls without a name and buttons other than the submitter are skipped.
+// The submitter may be nil for a submission without a button.
+func (f *HtmlForm) Entries(submitter *FormControl) (entries []FormEntry) {
+	for _, c := range f.Controls {
+		if c.Disabled || isInDatalist(c.Node.Raw()) {
+			continue
+		}
+		switch c.Type {
+		case "submit", "reset", "button", "image":
+			if c != submitter || c.Type == "reset" || c.Type == "button" {
+				continue
+			}
+		case "checkbox", "radio":
+			if !c.Checked {
+				continue
+			}
+		}
+		if c.Type == "image" {
+			prefix := ""
+			if c.Name != "" {
+				prefix = c.Name + "."
+			}
+			entries = append(entries, FormEntry{Name: prefix + "x", Value: "0"}, FormEntry{Name: prefix + "y", Value: "0"})
+			continue
+		}
+		if c.Name == "" {
+			continue
+		}
+		switch c.Type {
+		case "select", "select-multiple":
+			for _, o := range c.Options {
+				if o.Selected && !o.Disabled {
+					entries = append(entries, FormEntry{Name: c.Name, Value: o.Value})
+				}
+			}
+		case "file":
+			file := c.File
+			if file == nil {
+				file = &FormFile{ContentType: "application/octet-stream"}
+			}
+			entries = append(entries, FormEntry{Name: c.Name, File: file})
+		case "hidden":
+			if strings.EqualFold(c.Name, "_charset_") && !c.Node.HasAttr("value") {
+				entries = append(entries, FormEntry{Name: c.Name, Value: "UTF-8"})
+			} else {
+				entries = append(entries, FormEntry{Name: c.Name, Value: c.Value})
+			}
+		default:
+			entries = append(entries, FormEntry{Name: c.Name, Value: c.Value})
+		}
+		if dirname, ok := c.Node.Attrs().Get("dirname"); ok && dirname != "" && (c.Type == "text" || c.Type == "search" || c.Type == "textarea") {
+			entries = append(entries, FormEntry{Name: dirname, Value: "ltr"})
+		}
+	}
+	return
+}
+
+func isInDatalist(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "datalist" {
+			return true
+		}
+	}
+	return false
+}
+
+// Values returns the form data set as url.Values. Files are given by their filename.
+func (f *HtmlForm) Values(submitter *FormControl) url.Values {
+	values := make(url.Values)
+	for _, e := range f.Entries(submitter) {
+		if e.File != nil {
+			values.Add(e.Name, e.File.Filename)
+		} else {
+			values.Add(e.Name, e.Value)
+		}
+	}
+	return values
+}
+
+// submission returns the action, method and enctype of a submission, taking the formaction,
+// formmethod and formenctype of the submitter into account.
+func (f *HtmlForm) submission(submitter *FormControl) (action *url.URL, method, enctype string) {
+	attrs := f.Node.Attrs()
+	get := func(form, button string) (string, bool) {
+		if submitter != nil {
+			if v, ok := submitter.Node.Attrs().Get(button); ok {
+				return v, true
+			}
+		}
+		return attrs.Get(form)
+	}
+	// An empty or missing action submits to the document URL, not the base URL,
+	// and an empty formaction does not fall back to the action of the form.
+	action = f.page
+	if s, _ := get("action", "formaction"); strings.TrimSpace(s) != "" {
+		if u, err := url.Parse(strings.TrimSpace(s)); err == nil {
+			if f.base != nil {
+				u = f.base.ResolveReference(u)
+			}
+			action = u
+		}
+	}
+	method = "GET"
+	if s, _ := get("method", "formmethod"); strings.EqualFold(s, "post") || strings.EqualFold(s, "dialog") {
+		method = strings.ToUpper(s)
+	}
+	enctype = "application/x-www-form-urlencoded"
+	if s, _ := get("enctype", "formenctype"); strings.EqualFold(s, "multipart/form-data") || strings.EqualFold(s, "text/plain") {
+		enctype = strings.ToLower(s)
+	}
+	return
+}
+
+// Encode encodes the form data set as the body of a POST submission with the given submitter,
+// which may be nil, and returns it with its content type. Line breaks in names and values are normalized to CRLF.
+func (f *HtmlForm) Encode(submitter *FormControl) (body []byte, contentType string, err error) {
+	_, _, enctype := f.submission(submitter)
+	entries := f.Entries(submitter)
+	switch enctype {
+	case "multipart/form-data":
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		for _, e := range entries {
+			if e.File == nil {
+				if err := w.WriteField(normalizeNewlines(e.Name), normalizeNewlines(e.Value)); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+			file := e.File
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(e.Name), escapeQuotes(file.Filename)))
+			ct := file.ContentType
+			if ct == "" {
+				ct = "application/octet-stream"
+			}
+			h.Set("Content-Type", ct)
+			part, err := w.CreatePart(h)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(file.Content); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return b.Bytes(), w.FormDataContentType(), nil
+	case "text/plain":
+		var b strings.Builder
+		for _, e := range entries {
+			v := e.Value
+			if e.File != nil {
+				v = e.File.Filename
+			}
+			b.WriteString(normalizeNewlines(e.Name) + "=" + normalizeNewlines(v) + "\r\n")
+		}
+		return []byte(b.String()), "text/plain", nil
+	default:
+		return []byte(encodeEntries(entries)), "application/x-www-form-urlencoded", nil
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "%22", "\r", "%0D", "\n", "%0A")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// encodeEntries encodes entries in application/x-www-form-urlencoded, keeping their order
+// and normalizing line breaks to CRLF.
+func encodeEntries(entries []FormEntry) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		v := e.Value
+		if e.File != nil {
+			v = e.File.Filename
+		}
+		b.WriteString(url.QueryEscape(normalizeNewlines(e.Name)) + "=" + url.QueryEscape(normalizeNewlines(v)))
+	}
+	return b.String()
+}
+
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+// Request returns the HTTP request submitting the form with the given submitter, which may be nil.
+// GET submissions replace the query of the action URL with the form data set.
+func (f *HtmlForm) Request(submitter *FormControl) (*http.Request, error) {
+	action, method, _ := f.submission(submitter)
+	if action == nil || !action.IsAbs() {
+		return nil, errors.New("node: form action is not an absolute URL")
+	}
+	switch method {
+	case "GET":
+		u := *action
+		u.RawQuery = encodeEntries(f.Entries(submitter))
+		return http.NewRequest(http.MethodGet, u.String(), nil)
+	case "POST":
+		body, contentType, err := f.Encode(submitter)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodPost, action.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	default:
+		return nil, fmt.Errorf("node: cannot submit form with method %s", method)
+	}
+}
@@ -0,0 +1,182 @@
+package node
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseForm(t *testing.T) {
+	doc, err := ParseHTML(`<html><head><base href="/app/"></head><body>
+<form id="login" action="session" method="post">
+  <input type="hidden" name="token" value="abc">
+  <input name="user" value="guest" dirname="user.dir">
+  <input type="password" name="pass">
+  <input type="checkbox" name="remember">
+  <input type="checkbox" name="tags" value="a" checked><input type="checkbox" name="tags" value="b">
+  <input type="radio" name="mode" value="fast" checked><input type="radio" name="mode" value="safe">
+  <select name="lang"><option value="en">English</option><option selected>Deutsch</option></select>
+  <select name="multi" multiple><option selected>x</option><option disabled selected>y</option><option>z</option></select>
+  <textarea name="note">line1
+line2</textarea>
+  <fieldset disabled><legend><input name="inlegend" value="1"></legend><input name="off" value="2"></fieldset>
+  <input name="disabled" value="3" disabled>
+  <input type="file" name="avatar">
+  <button name="action" value="login">Log in</button>
+  <button type="submit" name="action" value="register" formaction="/register" formenctype="multipart/form-data">Register</button>
+  <input type="reset" name="reset">
+</form>
+<input name="outside" value="4" form="login">
+<input name="other" value="5">
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseForm(doc.Find(0, Body), nil); err != ErrNotForm {
+		t.Errorf("expected error %v; got %v", ErrNotForm, err)
+	}
+	page, _ := url.Parse("https://example.com/index.html")
+	form, err := ParseForm(doc.Find(0, Form), page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Action.String() != "https://example.com/app/session" || form.Method != "POST" || form.Enctype != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected action %v, method %q or enctype %q", form.Action, form.Method, form.Enctype)
+	}
+	if n := len(form.Controls); n != 19 {
+		t.Errorf("expected controls %d; got %d", 19, n)
+	}
+
+	expected := "token=abc&user=guest&user.dir=ltr&pass=&tags=a&mode=fast&lang=Deutsch&multi=x&note=line1%0D%0Aline2&inlegend=1&avatar=&outside=4"
+	if body, contentType, err := form.Encode(nil); err != nil {
+		t.Fatal(err)
+	} else if string(body) != expected || contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("expected body\n%s\ngot\n%s (%s)", expected, body, contentType)
+	}
+
+	for name, values := range map[string][]string{
+		"user": {"admin"}, "pass": {"secret"}, "remember": {"on"}, "tags": {"b"}, "mode": {"safe"}, "lang": {"en"}, "multi": {"x", "z"},
+	} {
+		if err := form.Set(name, values...); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for name, values := range map[string][]string{"lang": {"fr"}, "mode": {"slow"}, "missing": {"x"}, "action": {"x"}, "avatar": {"x"}} {
+		if err := form.Set(name, values...); err == nil {
+			t.Errorf("%s: expected error; got nil", name)
+		}
+	}
+	if err := form.Set("mode", "fast", "safe"); err == nil {
+		t.Error("mode: expected error for several radio values; got nil")
+	}
+	values := form.Values(form.Control("action"))
+	if expected := []string{"safe"}; !reflect.DeepEqual(values["mode"], expected) {
+		t.Errorf("expected mode %q; got %q", expected, values["mode"])
+	}
+	if expected := []string{"login"}; !reflect.DeepEqual(values["action"], expected) {
+		t.Errorf("expected action %q; got %q", expected, values["action"])
+	}
+	if expected := []string{"x", "z"}; !reflect.DeepEqual(values["multi"], expected) {
+		t.Errorf("expected multi %q; got %q", expected, values["multi"])
+	}
+	if values.Get("remember") != "on" || values.Get("tags") != "b" || values.Get("mode") != "safe" || values.Get("pass") != "secret" {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	if err := form.SetFile("avatar", &FormFile{Filename: "me.png", ContentType: "image/png", Content: []byte("PNG")}); err != nil {
+		t.Fatal(err)
+	}
+	register := form.Controls[slices.IndexFunc(form.Controls, func(c *FormControl) bool { return c.Value == "register" })]
+	req, err := form.Request(register)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://example.com/register" {
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("unexpected content type %q", req.Header.Get("Content-Type"))
+	}
+	r := multipart.NewReader(req.Body, params["boundary"])
+	parts := map[string]string{}
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p)
+		parts[p.FormName()] = p.FileName() + ":" + string(b)
+	}
+	if parts["avatar"] != "me.png:PNG" || parts["action"] != ":register" || parts["user"] != ":admin" {
+		t.Errorf("unexpected parts %v", parts)
+	}
+
+	form.Node.Raw().Attr = nil
+	form, err = ParseForm(form.Node, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err = form.Request(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || !strings.HasPrefix(req.URL.String(), "https://example.com/index.html?token=abc&user=guest") {
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+
+	doc, err = ParseHTML(`<base href="/app/"><form action="search"><button formaction="">Go</button></form>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form, err = ParseForm(doc.Find(0, Form), page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req, err = form.Request(form.Controls[0]); err != nil {
+		t.Fatal(err)
+	} else if expected := "https://example.com/index.html"; req.URL.String() != expected {
+		t.Errorf("expected URL %q; got %q", expected, req.URL)
+	}
+
+	doc, err = ParseHTML(`<form method="post" enctype="text/plain"><input name="q"><input name="q">
+<input type="radio" name="r" value="1" checked><input type="radio" name="r" value="2" checked>
+<select name="s" size="3"><option>a</option></select><select name="t"><option>b</option></select></form>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form, err = ParseForm(doc.Find(0, Form), page); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Set("q", "a", "b", "c"); err == nil {
+		t.Error("q: expected error for more values than controls; got nil")
+	}
+	if err := form.Set("q", "a\nb", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if body, _, err := form.Encode(nil); err != nil {
+		t.Fatal(err)
+	} else if expected := "q=a\r\nb\r\nq=c\r\nr=2\r\nt=b\r\n"; string(body) != expected {
+		t.Errorf("expected body %q; got %q", expected, body)
+	}
+	form.Node.Raw().Attr[1].Val = "multipart/form-data"
+	body, contentType, err := form.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ = mime.ParseMediaType(contentType)
+	p, err := multipart.NewReader(strings.NewReader(string(body)), params["boundary"]).NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(p); string(b) != "a\r\nb" {
+		t.Errorf("expected part %q; got %q", "a\r\nb", b)
+	}
+}
@@ -0,0 +1,42 @@
+package node
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// NewElement returns a detached element node with the specified tag name.
+// It is mainly useful as the context of ParseFragment.
+func NewElement(tag string) Node {
+	tag = strings.ToLower(tag)
+	return NewNode(&html.Node{Type: html.ElementNode, DataAtom: atom.Lookup([]byte(tag)), Data: tag})
+}
+
+// ParseFragment parses a fragment of HTML from the given Reader as if it were
+// the inner HTML of the context element and returns the top-level nodes found.
+// For example, `<tr>` snippets should be parsed in a "tbody" context and
+// `<option>` snippets in a "select" context.
+// If context is nil, the fragment is parsed in a "body" context.
+func ParseFragment(r io.Reader, context Node) ([]Node, error) {
+	return ParseFragmentWithOptions(r, context)
+}
+
+// ParseFragmentWithOptions is like ParseFragment, with options.
+func ParseFragmentWithOptions(r io.Reader, context Node, opts ...html.ParseOption) ([]Node, error) {
+	if context == nil {
+		context = NewElement("body")
+	}
+	nodes, err := html.ParseFragmentWithOptions(r, context.Raw(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return toNodes(nodes), nil
+}
+
+// ParseHTMLFragment returns the nodes of the HTML fragment from string parsed in the context element.
+func ParseHTMLFragment(s string, context Node) ([]Node, error) {
+	return ParseFragment(strings.NewReader(s), context)
+}
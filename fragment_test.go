@@ -0,0 +1,55 @@
+package node
+
+import "testing"
+
+func TestParseFragment(t *testing.T) {
+	rows, err := ParseHTMLFragment(`<tr><td>a</td><td>1</td></tr><tr><td>b</td><td>2</td></tr>`, NewElement("tbody"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected nodes %d; got %d", 2, len(rows))
+	}
+	for i, expected := range []string{"a", "b"} {
+		if name := rows[i].Data(); name != "tr" {
+			t.Errorf("expected name %q; got %q", "tr", name)
+		}
+		if td := rows[i].Find(0, Td); td == nil {
+			t.Errorf("expected td; got nil")
+		} else if text := td.GetText(); text != expected {
+			t.Errorf("expected text %q; got %q", expected, text)
+		}
+	}
+
+	options, err := ParseHTMLFragment(`<option value="1">One</option><option value="2" selected>Two</option>`, NewElement("select"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options) != 2 {
+		t.Fatalf("expected nodes %d; got %d", 2, len(options))
+	} else if value, _ := options[1].Attrs().Get("value"); value != "2" {
+		t.Errorf("expected value %q; got %q", "2", value)
+	}
+
+	doc, err := ParseHTML(`<ul id="list"></ul>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := ParseHTMLFragment(`<li>x</li><li>y</li>`, doc.Find(0, Ul))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].GetText() != "y" {
+		t.Errorf("unexpected items %v", items)
+	}
+
+	nodes, err := ParseHTMLFragment(`text <b>bold</b>`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected nodes %d; got %d", 2, len(nodes))
+	} else if html := nodes[1].Readable(); html != "<b>bold</b>" {
+		t.Errorf("expected html %q; got %q", "<b>bold</b>", html)
+	}
+}
@@ -6,10 +6,9 @@ require (
 	github.com/antchfx/htmlquery v1.3.6
 	github.com/antchfx/xpath v1.3.6
 	github.com/ericchiang/css v1.4.0
+	go.yaml.in/yaml/v3 v3.0.4
 	golang.org/x/net v0.54.0
+	golang.org/x/text v0.37.0
 )
 
-require (
-	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
-	golang.org/x/text v0.37.0 // indirect
-)
+require github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
@@ -0,0 +1,340 @@
+package node
+
+import (
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	indexesMu  sync.Mutex
+	indexes    sync.Map // map[weak.Pointer[html.Node]][]*Index, keyed by the top of the indexed tree
+	indexCount atomic.Int64
+)
+
+// simpleSelector matches css selectors made of a single tag name, id or class.
+var simpleSelector = regexp.MustCompile(`^([#.]?)([A-Za-z_][A-Za-z0-9_-]*)$`)
+
+// Index is a lookup table built once from a root node which maps ids, class tokens
+// and tag names to the element nodes beneath it.
+//
+// Once created, Find, FindN, FindAll, Select and SelectAll called on the root or any
+// of its descendants consult the index automatically when the filters permit, as long as
+// the root stays in the document it was in when the index was built.
+//
+// The index holds the tree weakly: it does not keep the tree alive and is unregistered
+// once the root is garbage collected, or earlier by Release.
+//
+// The index is not updated when the tree is changed through Raw. Removed nodes and changed
+// attributes are filtered out of the results, but added nodes and added ids or classes
+// are missed until the index is rebuilt, so call Invalidate after adding to the tree.
+// Sanitize and ExtractArticle invalidate the index themselves.
+type Index struct {
+	root weak.Pointer[html.Node]
+	tree weak.Pointer[html.Node] // the top of the tree when the index was built
+
+	mu      sync.RWMutex
+	stale   bool
+	ids     map[string][]weak.Pointer[html.Node]
+	classes map[string][]weak.Pointer[html.Node]
+	tags    map[string][]weak.Pointer[html.Node]
+}
+
+// NewIndex builds an Index for the tree rooted at root and registers it,
+// replacing any index previously registered for the same root.
+// See Index for when the index must be invalidated.
+func NewIndex(root Node) *Index {
+	r := root.Raw()
+	idx := &Index{root: weak.Make(r), tree: weak.Make(treeRoot(r))}
+	idx.build(r)
+	indexesMu.Lock()
+	var list []*Index
+	if v, ok := indexes.Load(idx.tree); ok {
+		list = v.([]*Index)
+	}
+	// The registered lists are never modified, so that lookups need no lock.
+	if i := slices.IndexFunc(list, func(i *Index) bool { return i.root == idx.root }); i >= 0 {
+		list = slices.Clone(list)
+		list[i] = idx
+	} else {
+		list = append(slices.Clip(list), idx)
+		indexCount.Add(1)
+	}
+	indexes.Store(idx.tree, list)
+	indexesMu.Unlock()
+	runtime.AddCleanup(r, (*Index).Release, idx)
+	return idx
+}
+
+func (idx *Index) build(root *html.Node) {
+	idx.ids = make(map[string][]weak.Pointer[html.Node])
+	idx.classes = make(map[string][]weak.Pointer[html.Node])
+	idx.tags = make(map[string][]weak.Pointer[html.Node])
+	add := func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		p := weak.Make(n)
+		idx.tags[n.Data] = append(idx.tags[n.Data], p)
+		if id, ok := getAttribute(NewNode(n), "id"); ok {
+			idx.ids[id] = append(idx.ids[id], p)
+		}
+		if class, ok := getAttribute(NewNode(n), "class"); ok {
+			seen := make(map[string]bool)
+			for _, i := range strings.Fields(class) {
+				if !seen[i] {
+					seen[i] = true
+					idx.classes[i] = append(idx.classes[i], p)
+				}
+			}
+		}
+	}
+	if root != nil {
+		add(root)
+		for n := range root.Descendants() {
+			add(n)
+		}
+	}
+	idx.stale = false
+}
+
+// Invalidate marks the index as out of date. It will be rebuilt the next time it is used.
+func (idx *Index) Invalidate() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.stale = true
+}
+
+// Rebuild rebuilds the index immediately.
+func (idx *Index) Rebuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.build(idx.root.Value())
+}
+
+// Release unregisters the index so that it is no longer consulted by find methods.
+func (idx *Index) Release() {
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	v, ok := indexes.Load(idx.tree)
+	if !ok {
+		return
+	}
+	list := v.([]*Index)
+	i := slices.Index(list, idx)
+	if i < 0 {
+		return
+	}
+	if list = slices.Delete(slices.Clone(list), i, i+1); len(list) == 0 {
+		indexes.Delete(idx.tree)
+	} else {
+		indexes.Store(idx.tree, list)
+	}
+	indexCount.Add(-1)
+}
+
+// Id returns the first element whose id attribute equals id, or nil if there is none.
+func (idx *Index) Id(id string) Node {
+	ids, _, _ := idx.maps()
+	for _, p := range ids[id] {
+		if n := p.Value(); n != nil {
+			return NewNode(n)
+		}
+	}
+	return nil
+}
+
+// Class returns all elements whose class attribute contains the class token.
+func (idx *Index) Class(class string) []Node {
+	_, classes, _ := idx.maps()
+	return toNodes(strongNodes(classes[class]))
+}
+
+// Tag returns all elements with the tag name.
+func (idx *Index) Tag(tag string) []Node {
+	_, _, tags := idx.maps()
+	return toNodes(strongNodes(tags[strings.ToLower(tag)]))
+}
+
+// maps returns the lookup tables, rebuilding the index first if it is stale.
+func (idx *Index) maps() (ids, classes, tags map[string][]weak.Pointer[html.Node]) {
+	idx.mu.RLock()
+	if idx.stale {
+		idx.mu.RUnlock()
+		idx.mu.Lock()
+		if idx.stale {
+			idx.build(idx.root.Value())
+		}
+		idx.mu.Unlock()
+		idx.mu.RLock()
+	}
+	defer idx.mu.RUnlock()
+	return idx.ids, idx.classes, idx.tags
+}
+
+// candidates returns the smallest list of nodes that may satisfy the tag filter and filters,
+// and false if none of them can be answered from the index.
+func (idx *Index) candidates(t TagFilter, filters []Filter) (res []*html.Node, ok bool) {
+	ids, classes, tags := idx.maps()
+	var best []weak.Pointer[html.Node]
+	try := func(nodes []weak.Pointer[html.Node]) {
+		if !ok || len(nodes) < len(best) {
+			best, ok = nodes, true
+		}
+	}
+	tryClass := func(class string) {
+		if fields := strings.Fields(class); len(fields) > 0 {
+			try(classes[fields[0]])
+		}
+	}
+	if t, isTag := t.(tag[string]); isTag && t.tag != "" {
+		try(tags[strings.ToLower(t.tag)])
+	}
+	for _, f := range filters {
+		switch f := f.(type) {
+		case attribute[string]:
+			switch f.name {
+			case "id":
+				try(ids[f.value])
+			case "class":
+				tryClass(f.value)
+			}
+		case class[string]:
+			tryClass(f.class)
+		case classStrict:
+			tryClass(string(f))
+		}
+	}
+	return strongNodes(best), ok
+}
+
+// lookupIndex returns the index registered for n or its nearest indexed ancestor.
+// Only the indexes of the tree n belongs to are considered, so a tree without any
+// costs a single lookup.
+func lookupIndex(n *html.Node) *Index {
+	if indexCount.Load() == 0 {
+		return nil
+	}
+	v, ok := indexes.Load(weak.Make(treeRoot(n)))
+	if !ok {
+		return nil
+	}
+	list := v.([]*Index)
+	for ; n != nil; n = n.Parent {
+		for _, idx := range list {
+			if idx.root.Value() == n {
+				return idx
+			}
+		}
+	}
+	return nil
+}
+
+// treeRoot returns the document node of the tree of n, or its topmost ancestor if it has none.
+func treeRoot(n *html.Node) *html.Node {
+	for n.Parent != nil && n.Type != html.DocumentNode {
+		n = n.Parent
+	}
+	return n
+}
+
+// invalidateIndex invalidates the index covering n, if any.
+func invalidateIndex(n *html.Node) {
+	if idx := lookupIndex(n); idx != nil {
+		idx.Invalidate()
+	}
+}
+
+// isDescendant reports whether n is a descendant of ancestor.
+func isDescendant(n, ancestor *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// findIndexed filters candidates from the index which are beneath n (or n itself when self is true).
+func (n *htmlNode) findIndexed(candidates []*html.Node, self bool, limit int, tag TagFilter, filters ...Filter) (nodes []Node) {
+	for _, c := range candidates {
+		if !(self && c == n.Node) && (c == n.Node || !isDescendant(c, n.Node)) {
+			continue
+		}
+		node := NewNode(c)
+		if tag != nil && !tag.IsMatch(node) {
+			continue
+		}
+		ok := true
+		for _, i := range filters {
+			if !i.IsMatch(node) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			nodes = append(nodes, node)
+			if len(nodes) == limit {
+				break
+			}
+		}
+	}
+	return
+}
+
+// selectIndexed answers simple css selectors from the index.
+// It returns false if the selector is not simple or there is no index.
+func (n *htmlNode) selectIndexed(sel string) ([]Node, bool) {
+	m := simpleSelector.FindStringSubmatch(strings.TrimSpace(sel))
+	if m == nil {
+		return nil, false
+	}
+	idx := lookupIndex(n.Node)
+	if idx == nil {
+		return nil, false
+	}
+	var (
+		tag     TagFilter
+		filters []Filter
+	)
+	switch m[1] {
+	case "#":
+		filters = append(filters, Id(m[2]))
+	case ".":
+		filters = append(filters, Class(m[2]))
+	default:
+		if atom.Lookup([]byte(m[2])) == 0 {
+			return nil, false
+		}
+		tag = Tag(m[2])
+	}
+	candidates, ok := idx.candidates(tag, filters)
+	if !ok {
+		return nil, false
+	}
+	return n.findIndexed(candidates, true, 0, tag, filters...), true
+}
+
+func toNodes(nodes []*html.Node) (res []Node) {
+	for _, i := range nodes {
+		res = append(res, NewNode(i))
+	}
+	return
+}
+
+// strongNodes returns the nodes of weak pointers which have not been collected.
+func strongNodes(nodes []weak.Pointer[html.Node]) (res []*html.Node) {
+	for _, p := range nodes {
+		if n := p.Value(); n != nil {
+			res = append(res, n)
+		}
+	}
+	return
+}
@@ -0,0 +1,154 @@
+package node
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestIndex(t *testing.T) {
+	doc, err := ParseHTML(`<div id="main" class="content wide"><p class="x">a</p><p class="x y">b</p></div><p id="other" class="y">c</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{}
+	finds := map[string]func() []Node{
+		"id":          func() []Node { return doc.FindAll(0, nil, Id("main")) },
+		"class":       func() []Node { return doc.FindAll(0, nil, Class("x")) },
+		"class multi": func() []Node { return doc.FindAll(0, P, Class("y x")) },
+		"tag":         func() []Node { return doc.FindAll(0, P) },
+		"nested":      func() []Node { return doc.Find(0, nil, Id("main")).FindAll(0, nil, Class("y")) },
+		"select id":   func() []Node { return doc.SelectAll("#other") },
+		"select tag":  func() []Node { return doc.Find(0, Div).SelectAll("div") },
+	}
+	for name, f := range finds {
+		expected[name] = len(f())
+	}
+
+	idx := NewIndex(doc)
+	defer idx.Release()
+	if node := idx.Id("main"); node == nil || node.Data() != "div" {
+		t.Errorf("expected div; got %v", node)
+	}
+	if nodes := idx.Class("y"); len(nodes) != 2 {
+		t.Errorf("expected nodes %d; got %d", 2, len(nodes))
+	}
+	if nodes := idx.Tag("P"); len(nodes) != 3 {
+		t.Errorf("expected nodes %d; got %d", 3, len(nodes))
+	}
+	for name, f := range finds {
+		if n := len(f()); n != expected[name] {
+			t.Errorf("%s: expected nodes %d; got %d", name, expected[name], n)
+		}
+	}
+	if nodes := doc.FindN(0, 1, P, Class("x")); len(nodes) != 1 {
+		t.Errorf("expected nodes %d; got %d", 1, len(nodes))
+	} else if text := nodes[0].GetText(); text != "a" {
+		t.Errorf("expected text %q; got %q", "a", text)
+	}
+
+	div := doc.Find(0, Div).Raw()
+	div.AppendChild(&html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.P,
+		Data:     "p",
+		Attr:     []html.Attribute{{Key: "id", Val: "new"}},
+	})
+	idx.Invalidate()
+	if node := doc.Find(0, nil, Id("new")); node == nil {
+		t.Error("expected new node after invalidate; got nil")
+	}
+	p := div.LastChild
+	div.RemoveChild(p)
+	if node := doc.Find(0, nil, Id("new")); node != nil {
+		t.Errorf("expected removed node to be filtered out; got %v", node)
+	}
+	div.AppendChild(p)
+	idx.Release()
+	if node := doc.Find(0, nil, Id("new")); node == nil {
+		t.Error("expected new node after release; got nil")
+	}
+}
+
+func TestIndexCollected(t *testing.T) {
+	count := indexCount.Load()
+	func() {
+		doc, err := ParseHTML(`<div id="main"></div>`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		NewIndex(doc)
+	}()
+	if n := indexCount.Load(); n != count+1 {
+		t.Fatalf("expected indexes %d; got %d", count+1, n)
+	}
+	for range 50 {
+		runtime.GC()
+		if indexCount.Load() == count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected index to be released with its tree; got indexes %d", indexCount.Load())
+}
+
+func TestIndexLookup(t *testing.T) {
+	doc, err := ParseHTML(`<div><p id="a">a</p></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseHTML(`<p id="a">b</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := indexCount.Load()
+	div, p := doc.Find(0, Div), doc.Find(0, P)
+	docIdx, divIdx := NewIndex(doc), NewIndex(div)
+	defer docIdx.Release()
+	if replaced := NewIndex(div); indexCount.Load() != count+2 {
+		t.Errorf("expected indexes %d; got %d", count+2, indexCount.Load())
+	} else {
+		divIdx = replaced
+	}
+	if idx := lookupIndex(p.Raw()); idx != divIdx {
+		t.Errorf("expected index of div; got %p", idx)
+	}
+	if idx := lookupIndex(other.Find(0, P).Raw()); idx != nil {
+		t.Errorf("expected no index for other document; got %p", idx)
+	}
+	divIdx.Release()
+	if idx := lookupIndex(p.Raw()); idx != docIdx {
+		t.Errorf("expected index of document; got %p", idx)
+	}
+	if indexCount.Load() != count+1 {
+		t.Errorf("expected indexes %d; got %d", count+1, indexCount.Load())
+	}
+}
+
+func BenchmarkFindId(b *testing.B) {
+	var sb strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&sb, `<div class="item"><span id="s%d">%d</span></div>`, i, i)
+	}
+	doc, err := ParseHTML(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Walk", func(b *testing.B) {
+		for b.Loop() {
+			doc.Find(0, nil, Id("s4999"))
+		}
+	})
+	b.Run("Index", func(b *testing.B) {
+		idx := NewIndex(doc)
+		defer idx.Release()
+		for b.Loop() {
+			doc.Find(0, nil, Id("s4999"))
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// JSONLD returns the items of the JSON-LD blocks (<script type="application/ld+json">)
+// in n and its descendants, in document order.
+//
+// A block may hold several values, one after another or in an array, and an object with
+// an @graph contributes the items of its graph instead of itself, which inherit its @context.
+// HTML comment and CDATA wrappers, trailing commas and raw line breaks in strings are tolerated.
+// Blocks which still cannot be parsed are skipped and reported in the returned error,
+// along with the items of the other blocks.
+func JSONLD(n HtmlNode) (items []map[string]any, err error) {
+	var errs []error
+	parse := func(c *html.Node) {
+		if c.Type != html.ElementNode || c.Data != "script" {
+			return
+		}
+		typ, _ := attributes(c.Attr).Get("type")
+		typ, _, _ = strings.Cut(typ, ";")
+		if !strings.EqualFold(strings.TrimSpace(typ), "application/ld+json") {
+			return
+		}
+		var src strings.Builder
+		for t := c.FirstChild; t != nil; t = t.NextSibling {
+			if t.Type == html.TextNode {
+				src.WriteString(t.Data)
+			}
+		}
+		values, err := parseJSONLD(src.String())
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, v := range values {
+			items = append(items, flattenJSONLD(v, nil)...)
+		}
+	}
+	parse(n.Raw())
+	for c := range n.Raw().Descendants() {
+		parse(c)
+	}
+	if len(errs) > 0 {
+		err = fmt.Errorf("node: invalid JSON-LD: %w", errors.Join(errs...))
+	}
+	return
+}
+
+// DecodeJSONLD decodes the JSON-LD items of n whose @type is typ into v, which must be a pointer
+// to a slice, receiving every such item, or to any other value, receiving the first one.
+// Types are compared ignoring a schema.org prefix, so "Product" matches "https://schema.org/Product".
+// It reports ErrNoMatch if no item has the type.
+func DecodeJSONLD(n HtmlNode, typ string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("node: DecodeJSONLD(non-pointer %v)", reflect.TypeOf(v))
+	}
+	items, _ := JSONLD(n)
+	var matched []map[string]any
+	for _, item := range items {
+		if hasJSONLDType(item, typ) {
+			matched = append(matched, item)
+		}
+	}
+	if len(matched) == 0 {
+		return ErrNoMatch
+	}
+	var b []byte
+	var err error
+	if rv.Elem().Kind() == reflect.Slice {
+		b, err = json.Marshal(matched)
+	} else {
+		b, err = json.Marshal(matched[0])
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func hasJSONLDType(item map[string]any, typ string) bool {
+	typ = trimSchemaOrg(typ)
+	switch t := item["@type"].(type) {
+	case string:
+		return trimSchemaOrg(t) == typ
+	case []any:
+		for _, t := range t {
+			if s, ok := t.(string); ok && trimSchemaOrg(s) == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func trimSchemaOrg(s string) string {
+	for _, prefix := range []string{"https://schema.org/", "http://schema.org/", "schema:"} {
+		if strings.HasPrefix(s, prefix) {
+			return s[len(prefix):]
+		}
+	}
+	return s
+}
+
+// parseJSONLD decodes the consecutive JSON values of a block after repairing common malformations.
+func parseJSONLD(s string) (values []any, err error) {
+	d := json.NewDecoder(strings.NewReader(repairJSON(s)))
+	for {
+		var v any
+		if err := d.Decode(&v); err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+}
+
+// repairJSON removes comment and CDATA wrappers and trailing commas,
+// and escapes line breaks and tabs inside strings.
+func repairJSON(s string) string {
+	s = strings.TrimSpace(s)
+	for _, wrapper := range [][2]string{{"<!--", "-->"}, {"//<![CDATA[", "//]]>"}, {"/*<![CDATA[*/", "/*]]>*/"}, {"<![CDATA[", "]]>"}} {
+		if strings.HasPrefix(s, wrapper[0]) {
+			s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s[len(wrapper[0]):]), wrapper[1]))
+		}
+	}
+	var b strings.Builder
+	var inString, escaped bool
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				b.WriteString(`\n`)
+				continue
+			case c == '\r':
+				b.WriteString(`\r`)
+				continue
+			case c == '\t':
+				b.WriteString(`\t`)
+				continue
+			}
+		case c == '"':
+			inString = true
+		case c == ',':
+			j := i + 1
+			for j < len(s) && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+				j++
+			}
+			if j < len(s) && (s[j] == '}' || s[j] == ']') {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// flattenJSONLD returns the items of a JSON-LD value, expanding arrays and @graph.
+func flattenJSONLD(v any, context any) (items []map[string]any) {
+	switch v := v.(type) {
+	case []any:
+		for _, v := range v {
+			items = append(items, flattenJSONLD(v, context)...)
+		}
+	case map[string]any:
+		if c, ok := v["@context"]; ok {
+			context = c
+		}
+		if graph, ok := v["@graph"]; ok {
+			return flattenJSONLD(graph, context)
+		}
+		if _, ok := v["@context"]; !ok && context != nil {
+			v["@context"] = context
+		}
+		items = append(items, v)
+	}
+	return
+}
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONLD(t *testing.T) {
+	doc, err := ParseHTML(`<html><head>
+<script type="application/ld+json">
+<!--
+{
+  "@context": "https://schema.org",
+  "@type": "Product",
+  "name": "Apple",
+  "description": "Crisp
+and fresh",
+  "offers": {"@type": "Offer", "price": 1.5,},
+}
+-->
+</script>
+<script type="application/ld+json; charset=utf-8">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "WebSite", "name": "Shop"},
+  {"@type": ["Thing", "schema:Product"], "name": "Kiwi"}
+]}
+{"@type": "Recipe", "name": "Pie"}
+</script>
+<script type="application/ld+json">{"broken": </script>
+<script type="text/javascript">{"@type": "Product"}</script>
+</head></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := JSONLD(doc)
+	if err == nil {
+		t.Error("expected error for broken block; got nil")
+	}
+	if len(items) != 4 {
+		t.Fatalf("expected items %d; got %d: %v", 4, len(items), items)
+	}
+	if desc := items[0]["description"]; desc != "Crisp\nand fresh" {
+		t.Errorf("expected description %q; got %q", "Crisp\nand fresh", desc)
+	}
+	if context := items[1]["@context"]; context != "https://schema.org" {
+		t.Errorf("expected inherited context; got %v", context)
+	}
+	if context, ok := items[3]["@context"]; ok {
+		t.Errorf("expected no context; got %v", context)
+	}
+
+	type product struct {
+		Name   string `json:"name"`
+		Offers struct {
+			Price float64 `json:"price"`
+		} `json:"offers"`
+	}
+	var products []product
+	if err := DecodeJSONLD(doc, "http://schema.org/Product", &products); err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 2 || products[0].Name != "Apple" || products[0].Offers.Price != 1.5 || products[1].Name != "Kiwi" {
+		t.Errorf("unexpected products %+v", products)
+	}
+	var recipe struct {
+		Name string `json:"name"`
+	}
+	if err := DecodeJSONLD(doc, "Recipe", &recipe); err != nil || recipe.Name != "Pie" {
+		t.Errorf("expected recipe %q; got %q (%v)", "Pie", recipe.Name, err)
+	}
+	if err := DecodeJSONLD(doc, "Event", &recipe); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected error %v; got %v", ErrNoMatch, err)
+	}
+}
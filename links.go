@@ -0,0 +1,105 @@
+package node
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Link is a URL referenced by an element.
+type Link struct {
+	// URL is the reference resolved against the base URL. It is only absolute if
+	// the page URL or the document's <base> element is.
+	URL *url.URL
+	// Text is the anchor text of <a>, or the alt text of <area> and <img>, with whitespace collapsed.
+	Text string
+	// Rel holds the link types of the rel attribute.
+	Rel []string
+	// Attr is the attribute the URL was found in, e.g. "href", "src" or "srcset".
+	Attr string
+	// Node is the element the link was found in.
+	Node Node
+}
+
+// linkAttrs lists the attributes holding URLs for each element.
+var linkAttrs = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"script": {"src"},
+	"iframe": {"src"},
+}
+
+func (n *node) Links(page *url.URL) []Link {
+	return links(n, page)
+}
+
+// Links is like Node.Links, using the document URL when page is nil.
+func (d *Document) Links(page *url.URL) []Link {
+	if page == nil {
+		page = d.url
+	}
+	return links(d.Node, page)
+}
+
+func links(n HtmlNode, page *url.URL) (res []Link) {
+	base := baseURL(n, page)
+	resolve := func(s string) (*url.URL, bool) {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(strings.ToLower(s), "javascript:") {
+			return nil, false
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, false
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		return u, true
+	}
+	res = elementLinks(n.Raw(), resolve)
+	for c := range n.Raw().Descendants() {
+		res = append(res, elementLinks(c, resolve)...)
+	}
+	return
+}
+
+func elementLinks(n *html.Node, resolve func(string) (*url.URL, bool)) (res []Link) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	attrs := attributes(n.Attr)
+	for _, attr := range linkAttrs[n.Data] {
+		v, ok := attrs.Get(attr)
+		if !ok {
+			continue
+		}
+		var refs []string
+		if attr == "srcset" {
+			candidates, _ := ParseSrcset(v)
+			for _, c := range candidates {
+				refs = append(refs, c.URL)
+			}
+		} else {
+			refs = []string{v}
+		}
+		for _, ref := range refs {
+			u, ok := resolve(ref)
+			if !ok {
+				continue
+			}
+			link := Link{URL: u, Rel: attrs.List("rel"), Attr: attr, Node: NewNode(n)}
+			if n.Data == "a" {
+				link.Text = strings.Join(strings.Fields(NewNode(n).GetText()), " ")
+			} else if alt, ok := attrs.Get("alt"); ok {
+				link.Text = strings.Join(strings.Fields(alt), " ")
+			}
+			res = append(res, link)
+		}
+	}
+	return
+}
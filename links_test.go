@@ -0,0 +1,62 @@
+package node
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><head>
+<base href="/docs/">
+<link rel="stylesheet" href="style.css">
+<script src="//cdn.example.com/app.js"></script>
+</head><body>
+<a href="intro.html" rel="next nofollow"> Read the
+  intro</a>
+<a href="javascript:void(0)">ignored</a>
+<a>no href</a>
+<img src="a.png" srcset="a-2x.png 2x, /b.png 3x" alt="An image">
+<map><area href="#top" alt="Top"></map>
+<iframe src="https://other.example.org/embed"></iframe>
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://example.com/a/page.html")
+	links := doc.Links(page)
+	var urls []string
+	for _, link := range links {
+		urls = append(urls, link.Attr+" "+link.URL.String())
+	}
+	expected := []string{
+		"href https://example.com/docs/style.css",
+		"src https://cdn.example.com/app.js",
+		"href https://example.com/docs/intro.html",
+		"src https://example.com/docs/a.png",
+		"srcset https://example.com/docs/a-2x.png",
+		"srcset https://example.com/b.png",
+		"href https://example.com/docs/#top",
+		"src https://other.example.org/embed",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected links\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(urls, "\n"))
+	}
+	if len(links) == len(expected) {
+		if a := links[2]; a.Text != "Read the intro" || !reflect.DeepEqual(a.Rel, []string{"next", "nofollow"}) || a.Node.Data() != "a" {
+			t.Errorf("unexpected link %+v", a)
+		}
+		if img := links[3]; img.Text != "An image" {
+			t.Errorf("expected text %q; got %q", "An image", img.Text)
+		}
+	}
+
+	doc.SetURL(page)
+	if links := doc.Find(0, Body).Links(nil); len(links) != 6 || links[0].URL.String() != "/docs/intro.html" {
+		t.Errorf("expected 6 links relative to base; got %v", links)
+	}
+	if links := doc.Links(nil); len(links) != 8 || links[0].URL.String() != "https://example.com/docs/style.css" {
+		t.Errorf("expected 8 links resolved against document URL; got %d", len(links))
+	}
+}
@@ -0,0 +1,320 @@
+package node
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MarkdownRule converts an element to Markdown. It receives the element and
+// the Markdown already converted from its children, and returns the Markdown for the element.
+// Block-level rules should surround their output with blank lines.
+type MarkdownRule func(n Node, content string) string
+
+// MarkdownConverter converts HTML to CommonMark, using GitHub Flavored Markdown for tables.
+// Its conversion of each tag can be replaced with AddRule.
+type MarkdownConverter struct {
+	rules map[string]MarkdownRule
+}
+
+// Markdown converts the node to Markdown with the default rules.
+func Markdown(n HtmlNode) string {
+	return NewMarkdownConverter().Convert(n)
+}
+
+// NewMarkdownConverter returns a MarkdownConverter with the default rules for headings,
+// emphasis, links, images, lists, code blocks, tables and blockquotes.
+func NewMarkdownConverter() *MarkdownConverter {
+	c := &MarkdownConverter{rules: make(map[string]MarkdownRule)}
+	heading := func(n Node, content string) string {
+		level, _ := strconv.Atoi(n.Data()[1:])
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.Join(strings.Fields(content), " ") + "\n\n"
+	}
+	for _, tag := range []string{"h1", "h2", "h3", "h4", "h5", "h6"} {
+		c.rules[tag] = heading
+	}
+	for _, tag := range []string{"b", "strong"} {
+		c.rules[tag] = func(_ Node, content string) string { return delimit(content, "**") }
+	}
+	for _, tag := range []string{"i", "em"} {
+		c.rules[tag] = func(_ Node, content string) string { return delimit(content, "_") }
+	}
+	for _, tag := range []string{"s", "del", "strike"} {
+		c.rules[tag] = func(_ Node, content string) string { return delimit(content, "~~") }
+	}
+	for _, tag := range []string{"ul", "ol"} {
+		c.rules[tag] = func(n Node, content string) string {
+			if p := n.Parent(); p != nil && p.Data() == "li" {
+				return "\n" + content + "\n"
+			}
+			return "\n\n" + content + "\n\n"
+		}
+	}
+	c.rules["li"] = markdownListItem
+	c.rules["a"] = markdownLink
+	c.rules["img"] = markdownImage
+	c.rules["code"] = markdownCode
+	c.rules["pre"] = markdownPre
+	c.rules["blockquote"] = markdownBlockquote
+	c.rules["br"] = func(Node, string) string { return "  \n" }
+	c.rules["hr"] = func(Node, string) string { return "\n\n---\n\n" }
+	c.rules["table"] = c.table
+	return c
+}
+
+// AddRule sets the rule used to convert elements with the tag name, replacing any existing rule.
+// A rule returning an empty string removes the element from the output.
+func (c *MarkdownConverter) AddRule(tag string, rule MarkdownRule) {
+	c.rules[strings.ToLower(tag)] = rule
+}
+
+// Convert converts the node to Markdown.
+func (c *MarkdownConverter) Convert(n HtmlNode) string {
+	var md string
+	if n.Type() == html.ElementNode {
+		md = c.element(n.Raw())
+	} else {
+		md = c.children(n.Raw())
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(md, "\n\n"))
+}
+
+var blankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+
+func (c *MarkdownConverter) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		var s string
+		switch child.Type {
+		case html.TextNode:
+			s = markdownText(child)
+		case html.ElementNode:
+			s = c.element(child)
+		}
+		// Whitespace collapsed within each node may still double up between nodes.
+		if strings.HasPrefix(s, " ") && !strings.HasPrefix(s, "  ") {
+			if md := b.String(); strings.HasSuffix(md, " ") || strings.HasSuffix(md, "\n") {
+				s = s[1:]
+			}
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+func (c *MarkdownConverter) element(n *html.Node) string {
+	if skip(n) {
+		return ""
+	}
+	content := c.children(n)
+	if rule, ok := c.rules[n.Data]; ok {
+		return rule(NewNode(n), content)
+	}
+	if isBlockElement(n) {
+		return "\n\n" + strings.TrimSpace(content) + "\n\n"
+	}
+	return content
+}
+
+// markdownText collapses whitespace of the text node, trims it next to block boundaries
+// and escapes Markdown syntax.
+func markdownText(n *html.Node) string {
+	s := collapseWhitespace(n.Data)
+	if prev := n.PrevSibling; (prev == nil && isBlockElement(n.Parent)) || (prev != nil && isBlockElement(prev)) {
+		s = strings.TrimLeft(s, " ")
+	}
+	if next := n.NextSibling; (next == nil && isBlockElement(n.Parent)) || (next != nil && isBlockElement(next)) {
+		s = strings.TrimRight(s, " ")
+	}
+	s = escapeMarkdown(s)
+	if atLineStart(n) {
+		s = escapeLineStart(s)
+	}
+	return s
+}
+
+// atLineStart reports whether no inline content precedes the text node n within its block,
+// so that its text starts a line of the Markdown output.
+func atLineStart(n *html.Node) bool {
+	for c := n; c != nil; c = c.Parent {
+		for p := c.PrevSibling; p != nil; p = p.PrevSibling {
+			switch {
+			case isBlockElement(p), p.Type == html.ElementNode && p.Data == "br":
+				return true
+			case p.Type == html.TextNode && strings.TrimSpace(p.Data) == "",
+				p.Type == html.CommentNode, p.Type == html.ElementNode && skip(p):
+			default:
+				return false
+			}
+		}
+		if c.Parent == nil || isBlockElement(c.Parent) {
+			return true
+		}
+	}
+	return true
+}
+
+var (
+	markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+		"<", "&lt;", "&", "&amp;")
+	markdownLeading = regexp.MustCompile(`^(\s*)(?:([#>+-])|(\d+)([.)]))`)
+)
+
+// escapeMarkdown escapes inline Markdown syntax and HTML, so that markup in text is not rendered.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeLineStart escapes a heading, quote or list marker at the start of a line.
+// Ordered list markers are escaped at the delimiter, as in 2024\., since digits cannot be escaped.
+func escapeLineStart(s string) string {
+	m := markdownLeading.FindStringSubmatchIndex(s)
+	switch {
+	case m == nil:
+		return s
+	case m[4] >= 0:
+		return s[:m[4]] + `\` + s[m[4]:]
+	default:
+		return s[:m[8]] + `\` + s[m[8]:]
+	}
+}
+
+// delimit wraps content with the delimiter, keeping surrounding whitespace outside.
+func delimit(content, delimiter string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return content
+	}
+	start := strings.Index(content, trimmed)
+	return content[:start] + delimiter + trimmed + delimiter + content[start+len(trimmed):]
+}
+
+func markdownListItem(n Node, content string) string {
+	marker := "- "
+	if p := n.Parent(); p != nil && p.Data() == "ol" {
+		i := 1
+		if start, err := p.Attrs().Int("start"); err == nil {
+			i = start
+		}
+		for prev := n.PrevSibling(); prev != nil; prev = prev.PrevSibling() {
+			if prev.Type() == html.ElementNode && prev.Data() == "li" {
+				i++
+			}
+		}
+		marker = strconv.Itoa(i) + ". "
+	}
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	return strings.Join(prefix(lines, marker, strings.Repeat(" ", len(marker))), "\n") + "\n"
+}
+
+func markdownLink(n Node, content string) string {
+	href, ok := n.Attrs().Get("href")
+	if !ok {
+		return content
+	}
+	title := ""
+	if t, ok := n.Attrs().Get("title"); ok && t != "" {
+		title = ` "` + strings.ReplaceAll(t, `"`, `\"`) + `"`
+	}
+	return "[" + strings.TrimSpace(content) + "](" + markdownURL(href) + title + ")"
+}
+
+func markdownImage(n Node, _ string) string {
+	src, ok := n.Attrs().Get("src")
+	if !ok {
+		return ""
+	}
+	alt, _ := n.Attrs().Get("alt")
+	title := ""
+	if t, ok := n.Attrs().Get("title"); ok && t != "" {
+		title = ` "` + strings.ReplaceAll(t, `"`, `\"`) + `"`
+	}
+	return "![" + escapeMarkdown(alt) + "](" + markdownURL(src) + title + ")"
+}
+
+// markdownURL encloses a URL in angle brackets if it contains spaces or parentheses.
+func markdownURL(u string) string {
+	u = strings.TrimSpace(u)
+	if strings.ContainsAny(u, " ()") {
+		return "<" + u + ">"
+	}
+	return u
+}
+
+func markdownCode(n Node, _ string) string {
+	if p := n.Parent(); p != nil && p.Data() == "pre" {
+		return n.GetText()
+	}
+	code := n.GetText()
+	delimiter := "`"
+	for strings.Contains(code, delimiter) {
+		delimiter += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return delimiter + code + delimiter
+}
+
+func markdownPre(n Node, _ string) string {
+	code := strings.TrimSuffix(n.GetText(), "\n")
+	var lang string
+	if c := n.Find(NoRecursive, Tag("code")); c != nil {
+		for _, class := range c.Attrs().List("class") {
+			if l, ok := strings.CutPrefix(class, "language-"); ok {
+				lang = l
+				break
+			}
+		}
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return "\n\n" + fence + lang + "\n" + code + "\n" + fence + "\n\n"
+}
+
+func markdownBlockquote(_ Node, content string) string {
+	lines := strings.Split(strings.TrimSpace(blankLines.ReplaceAllString(content, "\n\n")), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+}
+
+// table converts a table to a GitHub Flavored Markdown table.
+// The first row is used as the header, cells spanning columns are not expanded.
+func (c *MarkdownConverter) table(n Node, _ string) string {
+	var rows [][]string
+	for _, tr := range n.FindAll(Descendant, Tr) {
+		if tr.Find(Parent, Table).Raw() != n.Raw() {
+			continue // nested table
+		}
+		var row []string
+		for _, cell := range tr.FindAll(NoRecursive, Tags("td", "th")) {
+			s := strings.Join(strings.Fields(c.children(cell.Raw())), " ")
+			row = append(row, strings.ReplaceAll(s, "|", `\|`))
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	var cols int
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	var b strings.Builder
+	b.WriteString("\n\n")
+	for i, row := range rows {
+		row = append(row, make([]string, cols-len(row))...)
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
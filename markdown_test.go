@@ -0,0 +1,68 @@
+package node
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	doc, err := ParseHTML(`<html><head><title>ignored</title></head><body>
+<article>
+<h1>Weekly   <em>news</em></h1>
+<p>Read the <a href="https://example.com/a" title="First">first story</a> and <strong> the second</strong> one.
+Prices are 2*3_4.</p>
+<p><img src="/cat.png" alt="a cat"> inline <code>x := 1</code></p>
+<ul><li>apples</li><li>pears<ol start="3"><li>nested</li><li>again</li></ol></li></ul>
+<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}
+</code></pre>
+<blockquote><p>quoted</p><p>text</p></blockquote>
+<table><thead><tr><th>Name</th><th>Qty</th></tr></thead>
+<tbody><tr><td>a|b</td><td>10</td></tr><tr><td>kiwi</td></tr></tbody></table>
+<hr>
+<script>ignored()</script>
+</article>
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Weekly _news_\n\n" +
+		"Read the [first story](https://example.com/a \"First\") and **the second** one. Prices are 2\\*3\\_4.\n\n" +
+		"![a cat](/cat.png) inline `x := 1`\n\n" +
+		"- apples\n- pears\n  3. nested\n  4. again\n\n" +
+		"```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n\n" +
+		"> quoted\n>\n> text\n\n" +
+		"| Name | Qty |\n| --- | --- |\n| a\\|b | 10 |\n| kiwi |  |\n\n" +
+		"---"
+	if md := Markdown(doc.Find(0, Tag("article"))); md != expected {
+		t.Errorf("expected markdown\n%s\ngot\n%s", expected, md)
+	}
+
+	c := NewMarkdownConverter()
+	c.AddRule("a", func(_ Node, content string) string { return content })
+	c.AddRule("H1", func(Node, string) string { return "" })
+	c.AddRule("mark", func(_ Node, content string) string { return "==" + content + "==" })
+	doc, err = ParseHTML(`<h1>title</h1><p><a href="/x">link</a> and <mark>marked</mark></p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md := c.Convert(doc); md != "link and ==marked==" {
+		t.Errorf("expected markdown %q; got %q", "link and ==marked==", md)
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	for _, tc := range []struct{ html, expected string }{
+		{`<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp;amp;</p>`, `&lt;script>alert(1)&lt;/script> &amp;amp;`},
+		{`<p>In <b>May</b> 2024. Done</p>`, `In **May** 2024. Done`},
+		{`<p>2024. A year</p><p><span>1) first</span></p>`, "2024\\. A year\n\n1\\) first"},
+		{`<p># not a heading<br>- not a list</p>`, "\\# not a heading  \n\\- not a list"},
+		{`<ul><li>+ plus</li></ul>`, `- \+ plus`},
+	} {
+		doc, err := ParseHTML(tc.html)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if md := Markdown(doc); md != tc.expected {
+			t.Errorf("expected markdown %q; got %q", tc.expected, md)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package node
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageMetadata is the metadata of a page collected from its <title>, <meta> and <link> elements.
+// URLs are resolved against the base URL of the page.
+type PageMetadata struct {
+	Title       string
+	Description string
+	Keywords    []string
+	Author      string
+	// Lang is the lang attribute of <html>, or the Content-Language declared by a <meta> element.
+	Lang      string
+	Canonical *url.URL
+	// Favicon is the first icon link, falling back to an apple-touch-icon and,
+	// when the base URL is absolute, to /favicon.ico.
+	Favicon   *url.URL
+	OpenGraph OpenGraph
+	Twitter   TwitterCard
+}
+
+// OpenGraph holds the Open Graph properties of a page.
+type OpenGraph struct {
+	Title       string
+	Type        string
+	URL         *url.URL
+	Description string
+	SiteName    string
+	Locale      string
+	Images      []OpenGraphMedia
+	Videos      []OpenGraphMedia
+	Audio       []OpenGraphMedia
+	// Properties holds the values of every og: property by name without the prefix, e.g. "image:width".
+	Properties map[string][]string
+}
+
+// OpenGraphMedia is an og:image, og:video or og:audio with its structured properties.
+type OpenGraphMedia struct {
+	URL       *url.URL
+	SecureURL *url.URL
+	Type      string
+	Width     int
+	Height    int
+	Alt       string
+}
+
+// TwitterCard holds the Twitter Card properties of a page.
+type TwitterCard struct {
+	Card        string
+	Site        string
+	Creator     string
+	Title       string
+	Description string
+	Image       *url.URL
+	ImageAlt    string
+	// Properties holds the values of every twitter: property by name without the prefix.
+	Properties map[string][]string
+}
+
+// Metadata returns the metadata of the document containing n. Relative URLs are resolved against
+// the document's <base> element and, if n is a *Document, the document URL.
+// When a property is given more than once, the first value is used, except for Open Graph media,
+// where each og:image, og:video or og:audio starts a new item which the following structured
+// properties (e.g. og:image:width) describe. An og:image:url with the URL of the current item,
+// or for an item without one, describes that item rather than starting a new one.
+func Metadata(n HtmlNode) *PageMetadata {
+	var page *url.URL
+	if d, ok := n.(*Document); ok {
+		page = d.url
+	}
+	base := baseURL(n, page)
+	resolve := func(s string) *url.URL {
+		if s = strings.TrimSpace(s); s == "" {
+			return nil
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil
+		}
+		if base != nil {
+			return base.ResolveReference(u)
+		}
+		return u
+	}
+
+	root := n.Raw()
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	m := &PageMetadata{
+		OpenGraph: OpenGraph{Properties: make(map[string][]string)},
+		Twitter:   TwitterCard{Properties: make(map[string][]string)},
+	}
+	var appleTouchIcon *url.URL
+	var og []string // og properties in document order
+	for c := range root.Descendants() {
+		if c.Type != html.ElementNode || c.Namespace != "" {
+			continue
+		}
+		attrs := attributes(c.Attr)
+		switch c.Data {
+		case "html":
+			if lang, ok := attrs.Get("lang"); ok {
+				m.Lang = strings.TrimSpace(lang)
+			}
+		case "title":
+			if m.Title == "" {
+				m.Title = strings.Join(strings.Fields(NewNode(c).GetText()), " ")
+			}
+		case "link":
+			href, ok := attrs.Get("href")
+			if !ok {
+				continue
+			}
+			for _, rel := range attrs.List("rel") {
+				switch strings.ToLower(rel) {
+				case "canonical":
+					setURL(&m.Canonical, resolve(href))
+				case "icon":
+					setURL(&m.Favicon, resolve(href))
+				case "apple-touch-icon":
+					setURL(&appleTouchIcon, resolve(href))
+				}
+			}
+		case "meta":
+			content, ok := attrs.Get("content")
+			if !ok {
+				continue
+			}
+			content = strings.TrimSpace(content)
+			if equiv, _ := attrs.Get("http-equiv"); strings.EqualFold(equiv, "content-language") && m.Lang == "" {
+				m.Lang = content
+			}
+			for _, key := range []string{"property", "name"} {
+				name, _ := attrs.Get(key)
+				name = strings.ToLower(strings.TrimSpace(name))
+				switch {
+				case strings.HasPrefix(name, "og:"):
+					m.OpenGraph.Properties[name[3:]] = append(m.OpenGraph.Properties[name[3:]], content)
+					og = append(og, name[3:], content)
+				case strings.HasPrefix(name, "twitter:"):
+					m.Twitter.Properties[name[8:]] = append(m.Twitter.Properties[name[8:]], content)
+				case key == "name" && name == "description":
+					setString(&m.Description, content)
+				case key == "name" && name == "author":
+					setString(&m.Author, content)
+				case key == "name" && name == "keywords" && m.Keywords == nil:
+					for keyword := range strings.SplitSeq(content, ",") {
+						if keyword = strings.TrimSpace(keyword); keyword != "" {
+							m.Keywords = append(m.Keywords, keyword)
+						}
+					}
+				default:
+					continue
+				}
+				break
+			}
+		}
+	}
+	if m.Favicon == nil {
+		m.Favicon = appleTouchIcon
+	}
+	if m.Favicon == nil && base != nil && base.IsAbs() {
+		m.Favicon = base.ResolveReference(&url.URL{Path: "/favicon.ico"})
+	}
+
+	g := &m.OpenGraph
+	first := func(props map[string][]string, name string) string {
+		if v := props[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	g.Title, g.Type, g.Description = first(g.Properties, "title"), first(g.Properties, "type"), first(g.Properties, "description")
+	g.SiteName, g.Locale = first(g.Properties, "site_name"), first(g.Properties, "locale")
+	g.URL = resolve(first(g.Properties, "url"))
+	media := map[string]*[]OpenGraphMedia{"image": &g.Images, "video": &g.Videos, "audio": &g.Audio}
+	for i := 0; i < len(og); i += 2 {
+		name, content := og[i], og[i+1]
+		kind, property, _ := strings.Cut(name, ":")
+		items, ok := media[kind]
+		if !ok {
+			continue
+		}
+		switch property {
+		case "":
+			*items = append(*items, OpenGraphMedia{})
+		case "url":
+			// og:image:url describes the current item unless it already has another URL.
+			if len(*items) == 0 {
+				*items = append(*items, OpenGraphMedia{})
+			} else if u, v := (*items)[len(*items)-1].URL, resolve(content); u != nil && (v == nil || u.String() != v.String()) {
+				*items = append(*items, OpenGraphMedia{})
+			}
+		}
+		if len(*items) == 0 {
+			continue // structured property before any media
+		}
+		item := &(*items)[len(*items)-1]
+		switch property {
+		case "", "url":
+			item.URL = resolve(content)
+		case "secure_url":
+			item.SecureURL = resolve(content)
+		case "type":
+			item.Type = content
+		case "width":
+			item.Width, _ = strconv.Atoi(content)
+		case "height":
+			item.Height, _ = strconv.Atoi(content)
+		case "alt":
+			item.Alt = content
+		}
+	}
+
+	t := &m.Twitter
+	t.Card, t.Site, t.Creator = first(t.Properties, "card"), first(t.Properties, "site"), first(t.Properties, "creator")
+	t.Title, t.Description, t.ImageAlt = first(t.Properties, "title"), first(t.Properties, "description"), first(t.Properties, "image:alt")
+	if image := first(t.Properties, "image"); image != "" {
+		t.Image = resolve(image)
+	} else {
+		t.Image = resolve(first(t.Properties, "image:src"))
+	}
+	return m
+}
+
+func setString(s *string, v string) {
+	if *s == "" {
+		*s = v
+	}
+}
+
+func setURL(u **url.URL, v *url.URL) {
+	if *u == nil {
+		*u = v
+	}
+}
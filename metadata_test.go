@@ -0,0 +1,77 @@
+package node
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<!DOCTYPE html><html lang="en-GB"><head>
+<title> Fruit
+  shop </title>
+<meta name="description" content="Fresh fruit">
+<meta name="description" content="ignored">
+<meta name="keywords" content="fruit, apple, ,kiwi">
+<link rel="canonical" href="/shop">
+<link rel="shortcut icon" href="/icon.png">
+<meta property="og:title" content="Fruit shop">
+<meta property="og:type" content="website">
+<meta property="og:url" content="https://example.com/shop">
+<meta property="og:image" content="/a.png">
+<meta property="og:image:url" content="https://example.com/a.png">
+<meta property="og:image:width" content="300">
+<meta property="og:image:height" content="200">
+<meta property="og:image" content="https://cdn.example.com/b.png">
+<meta property="og:image:alt" content="Kiwi">
+<meta property="og:image:url" content="/c.png">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:site" content="@shop">
+<meta name="twitter:image" content="t.png">
+</head><body><svg><title>ignored</title></svg></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.SetURL(&url.URL{Scheme: "https", Host: "example.com", Path: "/dir/page"})
+	m := Metadata(doc)
+	if m.Title != "Fruit shop" || m.Description != "Fresh fruit" || m.Lang != "en-GB" {
+		t.Errorf("unexpected title %q, description %q or lang %q", m.Title, m.Description, m.Lang)
+	}
+	if expected := []string{"fruit", "apple", "kiwi"}; !reflect.DeepEqual(m.Keywords, expected) {
+		t.Errorf("expected keywords %q; got %q", expected, m.Keywords)
+	}
+	if m.Canonical.String() != "https://example.com/shop" || m.Favicon.String() != "https://example.com/icon.png" {
+		t.Errorf("unexpected canonical %v or favicon %v", m.Canonical, m.Favicon)
+	}
+	og := m.OpenGraph
+	if og.Title != "Fruit shop" || og.Type != "website" || og.URL.String() != "https://example.com/shop" {
+		t.Errorf("unexpected open graph %+v", og)
+	}
+	if len(og.Images) != 3 {
+		t.Fatalf("expected images %d; got %d", 3, len(og.Images))
+	}
+	if img := og.Images[0]; img.URL.String() != "https://example.com/a.png" || img.Width != 300 || img.Height != 200 {
+		t.Errorf("unexpected image %+v", img)
+	}
+	if img := og.Images[1]; img.URL.String() != "https://cdn.example.com/b.png" || img.Width != 0 || img.Alt != "Kiwi" {
+		t.Errorf("unexpected image %+v", img)
+	}
+	if img := og.Images[2]; img.URL.String() != "https://example.com/c.png" || img.Alt != "" {
+		t.Errorf("unexpected image %+v", img)
+	}
+	if tw := m.Twitter; tw.Card != "summary_large_image" || tw.Site != "@shop" || tw.Image.String() != "https://example.com/dir/t.png" {
+		t.Errorf("unexpected twitter card %+v", tw)
+	}
+
+	doc, err = ParseHTMLDocument(`<html><head><meta http-equiv="Content-Language" content="de"></head></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := Metadata(doc.Find(0, Head)); m.Lang != "de" || m.Favicon != nil {
+		t.Errorf("unexpected lang %q or favicon %v", m.Lang, m.Favicon)
+	}
+	doc.SetURL(&url.URL{Scheme: "https", Host: "example.com", Path: "/page"})
+	if m := Metadata(doc); m.Favicon.String() != "https://example.com/favicon.ico" {
+		t.Errorf("expected favicon %q; got %v", "https://example.com/favicon.ico", m.Favicon)
+	}
+}
@@ -0,0 +1,309 @@
+package node
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Item is a structured data item parsed from microdata or RDFa Lite.
+// Its JSON encoding follows the JSON form of the microdata specification.
+type Item struct {
+	Type []string `json:"type,omitempty"`
+	ID   string   `json:"id,omitempty"`
+	// Properties maps each property name to its values in tree order,
+	// which are strings or nested *Item.
+	Properties map[string][]any `json:"properties"`
+}
+
+// Microdata returns the top-level microdata items of the document containing n,
+// that is elements with itemscope which are not the value of a property,
+// following the microdata algorithm of the HTML specification, including itemref.
+// If n is not the document, only the items inside n are returned.
+// URL values are resolved against the base URL, which includes the document URL if n is a *Document.
+func Microdata(n HtmlNode) []*Item {
+	var page *url.URL
+	if d, ok := n.(*Document); ok {
+		page = d.url
+	}
+	root := n.Raw()
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	p := &microdataParser{root: root, base: baseURL(n, page)}
+	var items []*Item
+	if isItemscope(n.Raw()) && !hasAttr(n.Raw(), "itemprop") {
+		items = append(items, p.item(n.Raw(), nil))
+	}
+	for c := range n.Raw().Descendants() {
+		if isItemscope(c) && !hasAttr(c, "itemprop") {
+			items = append(items, p.item(c, nil))
+		}
+	}
+	return items
+}
+
+type microdataParser struct {
+	root *html.Node
+	base *url.URL
+	ids  map[string]*html.Node
+}
+
+func isItemscope(n *html.Node) bool {
+	return n.Type == html.ElementNode && hasAttr(n, "itemscope")
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	return attributes(n.Attr).has(key)
+}
+
+// item builds the item of the itemscope element n. memory holds the items being built,
+// so that cyclic itemrefs end with an "ERROR" value as the specification suggests.
+func (p *microdataParser) item(n *html.Node, memory []*html.Node) *Item {
+	attrs := attributes(n.Attr)
+	item := &Item{Type: attrs.List("itemtype"), Properties: make(map[string][]any)}
+	if id, ok := attrs.Get("itemid"); ok && len(item.Type) > 0 {
+		item.ID = p.resolve(id)
+	}
+	memory = append(memory, n)
+	for _, prop := range p.properties(n) {
+		var value any
+		if isItemscope(prop) {
+			if slices.Contains(memory, prop) {
+				value = "ERROR"
+			} else {
+				value = p.item(prop, memory)
+			}
+		} else {
+			value = p.value(prop)
+		}
+		for _, name := range attributes(prop.Attr).List("itemprop") {
+			item.Properties[name] = append(item.Properties[name], value)
+		}
+	}
+	return item
+}
+
+// properties returns the properties of the item n in tree order.
+func (p *microdataParser) properties(n *html.Node) (props []*html.Node) {
+	var pending []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		pending = append(pending, c)
+	}
+	for _, id := range attributes(n.Attr).List("itemref") {
+		if e := p.byID(id); e != nil {
+			pending = append(pending, e)
+		}
+	}
+	visited := map[*html.Node]bool{n: true}
+	for len(pending) > 0 {
+		c := pending[0]
+		pending = pending[1:]
+		if visited[c] {
+			continue
+		}
+		visited[c] = true
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if !isItemscope(c) {
+			var children []*html.Node
+			for child := c.FirstChild; child != nil; child = child.NextSibling {
+				children = append(children, child)
+			}
+			pending = append(children, pending...)
+		}
+		if len(attributes(c.Attr).List("itemprop")) > 0 {
+			props = append(props, c)
+		}
+	}
+	sortTreeOrder(p.root, props)
+	return
+}
+
+func (p *microdataParser) byID(id string) *html.Node {
+	if p.ids == nil {
+		p.ids = make(map[string]*html.Node)
+		for c := range p.root.Descendants() {
+			if c.Type == html.ElementNode {
+				if id, ok := attributes(c.Attr).Get("id"); ok {
+					if _, ok := p.ids[id]; !ok {
+						p.ids[id] = c
+					}
+				}
+			}
+		}
+	}
+	return p.ids[id]
+}
+
+// sortTreeOrder sorts nodes of the tree rooted at root in tree order.
+func sortTreeOrder(root *html.Node, nodes []*html.Node) {
+	if len(nodes) < 2 {
+		return
+	}
+	order := make(map[*html.Node]int, len(nodes))
+	for _, n := range nodes {
+		order[n] = -1
+	}
+	var i int
+	for c := range root.Descendants() {
+		if _, ok := order[c]; ok {
+			order[c] = i
+			i++
+		}
+	}
+	slices.SortStableFunc(nodes, func(a, b *html.Node) int { return order[a] - order[b] })
+}
+
+// value returns the property value of a non-itemscope element.
+func (p *microdataParser) value(n *html.Node) string {
+	attrs := attributes(n.Attr)
+	get := func(key string) string {
+		v, _ := attrs.Get(key)
+		return v
+	}
+	switch n.Data {
+	case "meta":
+		return get("content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return p.resolve(get("src"))
+	case "a", "area", "link":
+		return p.resolve(get("href"))
+	case "object":
+		return p.resolve(get("data"))
+	case "data", "meter":
+		return get("value")
+	case "time":
+		if v, ok := attrs.Get("datetime"); ok {
+			return v
+		}
+	}
+	return NewNode(n).GetText()
+}
+
+func (p *microdataParser) resolve(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	if p.base != nil {
+		u = p.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// RDFa returns the top-level RDFa Lite items of n and its descendants: elements with typeof
+// which are not the value of a property of another item. Types and properties are expanded
+// against vocab and prefix into absolute IRIs, except where no vocabulary is in scope.
+// Values are taken from content, href, src, datetime or the text, and resource gives the item ID.
+// URL values are resolved like Microdata.
+func RDFa(n HtmlNode) []*Item {
+	var page *url.URL
+	if d, ok := n.(*Document); ok {
+		page = d.url
+	}
+	p := &rdfaParser{microdataParser{base: baseURL(n, page)}}
+	var items []*Item
+	p.walk(n.Raw(), rdfaContext{prefixes: map[string]string{"schema": "http://schema.org/"}}, nil, &items)
+	return items
+}
+
+type rdfaParser struct {
+	microdataParser
+}
+
+type rdfaContext struct {
+	vocab    string
+	prefixes map[string]string
+}
+
+// expand expands a term, CURIE or IRI into an absolute IRI.
+func (c rdfaContext) expand(term string) string {
+	if prefix, ref, ok := strings.Cut(term, ":"); ok {
+		if iri, ok := c.prefixes[prefix]; ok {
+			return iri + ref
+		}
+		return term
+	}
+	return c.vocab + term
+}
+
+func (p *rdfaParser) walk(n *html.Node, ctx rdfaContext, item *Item, items *[]*Item) {
+	if n.Type != html.ElementNode && n.Type != html.DocumentNode {
+		return
+	}
+	attrs := attributes(n.Attr)
+	if vocab, ok := attrs.Get("vocab"); ok {
+		ctx.vocab = strings.TrimSpace(vocab)
+	}
+	if prefix, ok := attrs.Get("prefix"); ok {
+		prefixes := make(map[string]string, len(ctx.prefixes))
+		for k, v := range ctx.prefixes {
+			prefixes[k] = v
+		}
+		fields := strings.Fields(prefix)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if name, ok := strings.CutSuffix(fields[i], ":"); ok {
+				prefixes[name] = fields[i+1]
+			}
+		}
+		ctx.prefixes = prefixes
+	}
+
+	props := attrs.List("property")
+	typeOf, isItem := attrs.Get("typeof")
+	var child *Item
+	if isItem {
+		child = &Item{Properties: make(map[string][]any)}
+		for _, t := range strings.Fields(typeOf) {
+			child.Type = append(child.Type, ctx.expand(t))
+		}
+		if resource, ok := attrs.Get("resource"); ok {
+			child.ID = p.resolve(resource)
+		}
+	}
+	if item != nil && len(props) > 0 {
+		var value any
+		if child != nil {
+			value = child
+		} else {
+			value = p.rdfaValue(n, attrs)
+		}
+		for _, prop := range props {
+			name := ctx.expand(prop)
+			item.Properties[name] = append(item.Properties[name], value)
+		}
+	} else if child != nil {
+		*items = append(*items, child)
+	}
+	if child != nil {
+		item = child
+	} else if len(props) > 0 && item != nil {
+		return // the text of a literal property is not searched for nested properties
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c, ctx, item, items)
+	}
+}
+
+func (p *rdfaParser) rdfaValue(n *html.Node, attrs attributes) string {
+	if v, ok := attrs.Get("content"); ok {
+		return v
+	}
+	for _, key := range []string{"href", "src", "resource"} {
+		if v, ok := attrs.Get(key); ok {
+			return p.resolve(v)
+		}
+	}
+	if v, ok := attrs.Get("datetime"); ok && n.Data == "time" {
+		return v
+	}
+	return NewNode(n).GetText()
+}
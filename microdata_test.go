@@ -0,0 +1,84 @@
+package node
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestMicrodata(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><body>
+<div itemscope itemtype="https://schema.org/Product" itemid="/p/1" itemref="extra">
+  <span itemprop="name">Apple</span>
+  <img itemprop="image" src="apple.png">
+  <meta itemprop="sku" content="A1">
+  <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+    <data itemprop="price" value="1.50">$1.50</data>
+    <time itemprop="validUntil" datetime="2024-12-31">end of year</time>
+    <link itemprop="availability" href="https://schema.org/InStock">
+  </div>
+</div>
+<p id="extra"><span itemprop="brand name">Farm</span></p>
+<div itemscope><div id="cycle" itemprop="loop" itemscope><span itemprop="back" itemscope itemref="cycle"></span></div></div>
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.SetURL(&url.URL{Scheme: "https", Host: "example.com", Path: "/shop/"})
+	items := Microdata(doc)
+	if len(items) != 2 {
+		t.Fatalf("expected items %d; got %d", 2, len(items))
+	}
+	b, err := json.Marshal(items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":["https://schema.org/Product"],"id":"https://example.com/p/1","properties":{` +
+		`"brand":["Farm"],"image":["https://example.com/shop/apple.png"],"name":["Apple","Farm"],` +
+		`"offers":[{"type":["https://schema.org/Offer"],"properties":{"availability":["https://schema.org/InStock"],` +
+		`"price":["1.50"],"validUntil":["2024-12-31"]}}],"sku":["A1"]}}`
+	if string(b) != expected {
+		t.Errorf("expected json\n%s\ngot\n%s", expected, b)
+	}
+	loop, _ := items[1].Properties["loop"][0].(*Item)
+	if back, ok := loop.Properties["back"][0].(*Item); !ok || back.Properties["loop"][0] != "ERROR" {
+		t.Errorf("expected ERROR for cyclic itemref; got %v", items[1].Properties)
+	}
+	if items := Microdata(doc.Find(0, P)); len(items) != 0 {
+		t.Errorf("expected no items; got %d", len(items))
+	}
+}
+
+func TestRDFa(t *testing.T) {
+	doc, err := ParseHTML(`<body vocab="https://schema.org/" prefix="ex: https://example.org/ns#">
+<div typeof="Product" resource="#apple">
+  <span property="name">Apple</span>
+  <a property="url" href="https://example.com/apple">link</a>
+  <span property="ex:color">red</span>
+  <div property="offers" typeof="Offer">
+    <meta property="price" content="1.50">
+    <span property="priceCurrency">USD</span>
+  </div>
+</div>
+<span property="orphan">ignored</span>
+</body>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := RDFa(doc)
+	if len(items) != 1 {
+		t.Fatalf("expected items %d; got %d", 1, len(items))
+	}
+	b, err := json.Marshal(items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":["https://schema.org/Product"],"id":"#apple","properties":{` +
+		`"https://example.org/ns#color":["red"],"https://schema.org/name":["Apple"],` +
+		`"https://schema.org/offers":[{"type":["https://schema.org/Offer"],"properties":{` +
+		`"https://schema.org/price":["1.50"],"https://schema.org/priceCurrency":["USD"]}}],` +
+		`"https://schema.org/url":["https://example.com/apple"]}}`
+	if string(b) != expected {
+		t.Errorf("expected json\n%s\ngot\n%s", expected, b)
+	}
+}
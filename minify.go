@@ -0,0 +1,363 @@
+package node
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// RenderOptions controls how HTMLWithOptions renders a node.
+// The zero value renders like HTML. With any option set, void elements are written without
+// a trailing slash and only the characters which must be escaped are escaped.
+type RenderOptions struct {
+	// CollapseWhitespace replaces each run of whitespace in text with a single space and removes
+	// whitespace at the boundaries of block-level elements, where it is not rendered.
+	// Text inside <pre>, <textarea>, <listing>, <script> and <style> is kept as is.
+	CollapseWhitespace bool
+	// RemoveComments removes comment nodes.
+	RemoveComments bool
+	// OmitOptionalTags omits the end tags which the HTML specification allows to be omitted,
+	// such as </li>, </p>, </td> and </body>, where the following content allows it.
+	// The end tag of the rendered node itself is always written.
+	OmitOptionalTags bool
+	// OmitAttrQuotes writes attribute values without quotes where the value allows it,
+	// and empty values as the bare attribute name.
+	OmitAttrQuotes bool
+	// ShortBooleanAttrs writes boolean attributes, such as checked or disabled, as the bare attribute name
+	// when their value is empty or the attribute name, so that values such as hidden="until-found" are kept.
+	ShortBooleanAttrs bool
+}
+
+// MinifyOptions enables every option of RenderOptions.
+var MinifyOptions = RenderOptions{
+	CollapseWhitespace: true,
+	RemoveComments:     true,
+	OmitOptionalTags:   true,
+	OmitAttrQuotes:     true,
+	ShortBooleanAttrs:  true,
+}
+
+func (n *htmlNode) HTMLWithOptions(opts RenderOptions) string {
+	if opts == (RenderOptions{}) {
+		return n.HTML()
+	}
+	r := &minifier{opts: opts, root: n.Raw(), space: true}
+	for p := n.Raw().Parent; p != nil; p = p.Parent {
+		if preserveWhitespace[p.Data] && p.Type == html.ElementNode {
+			r.preserve++
+		}
+	}
+	r.render(n.Raw())
+	return r.b.String()
+}
+
+// rawTextElements are the elements whose text is written without escaping.
+var rawTextElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true,
+	"plaintext": true, "script": true, "style": true, "xmp": true,
+}
+
+// preserveWhitespace are the elements whose whitespace is significant.
+var preserveWhitespace = map[string]bool{
+	"listing": true, "plaintext": true, "pre": true, "script": true, "style": true, "textarea": true, "xmp": true,
+}
+
+// whitespaceBoundaries are the elements, besides block-level elements, at whose boundaries whitespace is not rendered.
+var whitespaceBoundaries = map[string]bool{
+	"base": true, "br": true, "col": true, "colgroup": true, "link": true, "meta": true, "noscript": true,
+	"optgroup": true, "option": true, "script": true, "search": true, "style": true,
+	"td": true, "template": true, "th": true,
+}
+
+// replacedElements are inline elements rendered as content, so that whitespace around them is significant.
+var replacedElements = map[string]bool{
+	"audio": true, "button": true, "canvas": true, "embed": true, "iframe": true, "img": true, "input": true,
+	"math": true, "meter": true, "object": true, "progress": true, "select": true, "svg": true,
+	"textarea": true, "video": true,
+}
+
+// booleanAttrs are the boolean attributes of HTML elements.
+var booleanAttrs = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true, "autoplay": true, "checked": true,
+	"controls": true, "default": true, "defer": true, "disabled": true, "formnovalidate": true,
+	"hidden": true, "inert": true, "ismap": true, "itemscope": true, "loop": true, "multiple": true,
+	"muted": true, "nomodule": true, "novalidate": true, "open": true, "playsinline": true,
+	"readonly": true, "required": true, "reversed": true, "selected": true,
+}
+
+type minifier struct {
+	b        strings.Builder
+	opts     RenderOptions
+	root     *html.Node
+	preserve int  // depth of elements preserving whitespace
+	space    bool // output is at a whitespace boundary or ends with a space
+	pending  bool // a collapsed space is pending
+}
+
+func isWhitespaceBoundary(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Namespace == "" && (blockElements[n.Data] || whitespaceBoundaries[n.Data])
+}
+
+// boundary drops the pending space at the boundary of a block.
+func (r *minifier) boundary() {
+	if r.opts.CollapseWhitespace {
+		r.pending = false
+		r.space = true
+	}
+}
+
+// inline writes the pending space before inline content.
+func (r *minifier) inline() {
+	if r.pending {
+		r.b.WriteByte(' ')
+		r.pending = false
+		r.space = true
+	}
+}
+
+func (r *minifier) render(n *html.Node) {
+	switch n.Type {
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			r.render(c)
+		}
+		r.pending = false
+	case html.DoctypeNode:
+		html.Render(&r.b, n)
+		r.boundary()
+	case html.CommentNode:
+		if !r.opts.RemoveComments {
+			r.inline()
+			r.b.WriteString("<!--" + n.Data + "-->")
+		}
+	case html.TextNode:
+		r.text(n)
+	case html.ElementNode:
+		r.element(n)
+	}
+	if n == r.root {
+		r.pending = false
+	}
+}
+
+func (r *minifier) text(n *html.Node) {
+	s := n.Data
+	if p := n.Parent; p != nil && p.Type == html.ElementNode && rawTextElements[p.Data] && p.Namespace == "" {
+		r.inline()
+		r.b.WriteString(s)
+		r.space = false
+		return
+	}
+	if r.opts.CollapseWhitespace && r.preserve == 0 {
+		s = collapseWhitespace(s)
+		if r.space || r.pending {
+			s = strings.TrimPrefix(s, " ")
+		}
+		if s == "" {
+			return
+		}
+		var trailing bool
+		s, trailing = strings.CutSuffix(s, " ")
+		if s != "" {
+			r.inline()
+			r.b.WriteString(escapeText(s))
+			r.space = false
+		}
+		r.pending = r.pending || trailing
+		return
+	}
+	r.inline()
+	r.b.WriteString(escapeText(s))
+	r.space = strings.HasSuffix(s, " ")
+}
+
+func (r *minifier) element(n *html.Node) {
+	if isWhitespaceBoundary(n) {
+		r.boundary()
+	} else {
+		r.inline()
+	}
+	r.b.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		r.attr(n, a)
+	}
+	if n.Namespace != "" && n.FirstChild == nil {
+		r.b.WriteString("/>")
+		r.end(n)
+		return
+	}
+	r.b.WriteByte('>')
+	if isVoidElement(n.Data) && n.Namespace == "" {
+		r.end(n)
+		return
+	}
+	if c := n.FirstChild; c != nil && c.Type == html.TextNode && strings.HasPrefix(c.Data, "\n") {
+		switch n.Data {
+		case "pre", "listing", "textarea":
+			// The parser drops a newline right after the start tag, so keep the first one of the text.
+			r.b.WriteByte('\n')
+		}
+	}
+	preserve := preserveWhitespace[n.Data] && n.Namespace == ""
+	if preserve {
+		r.preserve++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c)
+	}
+	if preserve {
+		r.preserve--
+	}
+	if n.Data == "plaintext" && n.Namespace == "" {
+		return
+	}
+	if isWhitespaceBoundary(n) {
+		r.boundary()
+	}
+	if n == r.root || !r.opts.OmitOptionalTags || !r.optionalEndTag(n) {
+		if !isWhitespaceBoundary(n) {
+			r.inline()
+		}
+		r.b.WriteString("</" + n.Data + ">")
+	}
+	r.end(n)
+}
+
+// end updates the whitespace state after the element n.
+func (r *minifier) end(n *html.Node) {
+	if isWhitespaceBoundary(n) {
+		r.boundary()
+	} else if replacedElements[n.Data] {
+		r.space = false
+	}
+}
+
+func (r *minifier) attr(n *html.Node, a html.Attribute) {
+	r.b.WriteByte(' ')
+	if a.Namespace != "" {
+		r.b.WriteString(a.Namespace + ":")
+	}
+	r.b.WriteString(a.Key)
+	switch {
+	case r.opts.ShortBooleanAttrs && n.Namespace == "" && a.Namespace == "" && booleanAttrs[a.Key] &&
+		(a.Val == "" || strings.EqualFold(a.Val, a.Key)):
+	case r.opts.OmitAttrQuotes && a.Val == "":
+	case r.opts.OmitAttrQuotes && unquotable(a.Val):
+		r.b.WriteString("=" + strings.ReplaceAll(a.Val, "&", "&amp;"))
+	default:
+		r.b.WriteString(`="` + strings.NewReplacer("&", "&amp;", `"`, "&#34;").Replace(a.Val) + `"`)
+	}
+}
+
+// unquotable reports whether an attribute value can be written without quotes.
+// A trailing slash is kept quoted, as it could be taken for a self-closing tag.
+func unquotable(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\n\f\r\"'=<>`") && !strings.HasSuffix(s, "/")
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// next returns the sibling rendered after n, skipping nodes which the options remove.
+func (r *minifier) next(n *html.Node) *html.Node {
+	c := n.NextSibling
+	for ; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.CommentNode && r.opts.RemoveComments:
+		// Whitespace after a block boundary is always removed when collapsing.
+		case c.Type == html.TextNode && r.opts.CollapseWhitespace && r.preserve == 0 &&
+			isWhitespaceBoundary(n) && strings.TrimSpace(c.Data) == "":
+		default:
+			return c
+		}
+	}
+	return nil
+}
+
+// pClosers are the elements whose start tag closes an open p element.
+var pClosers = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "dialog": true,
+	"div": true, "dl": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hgroup": true, "hr": true, "main": true, "menu": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "search": true, "section": true, "table": true, "ul": true,
+}
+
+// optionalEndTag reports whether the end tag of n may be omitted,
+// following the optional tags rules of the HTML specification.
+func (r *minifier) optionalEndTag(n *html.Node) bool {
+	if n.Namespace != "" {
+		return false
+	}
+	next := r.next(n)
+	is := func(tags ...string) bool {
+		if next == nil || next.Type != html.ElementNode || next.Namespace != "" {
+			return false
+		}
+		for _, tag := range tags {
+			if next.Data == tag {
+				return true
+			}
+		}
+		return false
+	}
+	// notSpaceOrComment reports whether n is not immediately followed by whitespace or a comment.
+	notSpaceOrComment := func() bool {
+		if next == nil {
+			return true
+		}
+		switch next.Type {
+		case html.CommentNode:
+			return false
+		case html.TextNode:
+			return next.Data != "" && strings.IndexByte(" \t\n\f\r", next.Data[0]) < 0
+		}
+		return true
+	}
+	switch n.Data {
+	case "html", "body":
+		return next == nil || next.Type != html.CommentNode
+	case "head", "colgroup", "caption":
+		return notSpaceOrComment()
+	case "li":
+		return next == nil || is("li")
+	case "dt":
+		return is("dt", "dd")
+	case "dd":
+		return next == nil || is("dt", "dd")
+	case "p":
+		if next == nil {
+			switch p := n.Parent; {
+			case p == nil, p.Type != html.ElementNode:
+				return true
+			case strings.Contains(p.Data, "-"):
+				return false
+			default:
+				switch p.Data {
+				case "a", "audio", "del", "ins", "map", "noscript", "video":
+					return false
+				}
+				return true
+			}
+		}
+		return next.Type == html.ElementNode && next.Namespace == "" && pClosers[next.Data]
+	case "rt", "rp":
+		return next == nil || is("rt", "rp")
+	case "optgroup":
+		return next == nil || is("optgroup", "hr")
+	case "option":
+		return next == nil || is("option", "optgroup", "hr")
+	case "thead":
+		return is("tbody", "tfoot")
+	case "tbody":
+		return next == nil || is("tbody", "tfoot")
+	case "tfoot":
+		return next == nil
+	case "tr":
+		return next == nil || is("tr")
+	case "td", "th":
+		return next == nil || is("td", "th")
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package node
+
+import "testing"
+
+func TestHTMLWithOptions(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<!DOCTYPE html>
+<html>
+  <head>
+    <title> Minify   test </title>
+    <!-- comment -->
+    <style> body { color: red; } </style>
+  </head>
+  <body>
+    <div id="main" class="a b">
+      <p>Hello,   <b>big </b> world!</p>
+      <p>An <img src="a.png" alt=""> image <a href="/x?a=1&amp;b=2" title='say "hi"'>link</a></p>
+      <pre>
+  keep   this
+    spacing</pre>
+      <ul>
+        <li>one</li>
+        <li>two</li>
+      </ul>
+      <input type="checkbox" checked="checked" disabled="">
+      <table><tr><td>1</td><td>2</td></tr></table>
+      <textarea>  raw  text </textarea>
+    </div>
+  </body>
+</html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `<!DOCTYPE html><html><head><title>Minify test</title><style> body { color: red; } </style><body>` +
+		`<div id=main class="a b"><p>Hello, <b>big </b>world!<p>An <img src=a.png alt> image <a href="/x?a=1&amp;b=2" title="say &#34;hi&#34;">link</a>` +
+		"<pre>  keep   this\n    spacing</pre>" +
+		`<ul><li>one<li>two</ul><input type=checkbox checked disabled><table><tbody><tr><td>1<td>2</table><textarea>  raw  text </textarea></div>`
+	if got := doc.HTMLWithOptions(MinifyOptions); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+	if expect, got := doc.HTML(), doc.HTMLWithOptions(RenderOptions{}); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+
+	ul := doc.Find(Descendant, Tag("ul"))
+	if expect, got := "<ul>\n        <li>one</li>\n        <li>two</li>\n      </ul>", ul.HTMLWithOptions(RenderOptions{OmitAttrQuotes: true}); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+	if expect, got := "<li>one</li>", ul.Find(Descendant, Tag("li")).HTMLWithOptions(MinifyOptions); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+	if expect, got := `<div id=main class="a b"><!-- c -->a <b>b</b></div>`, mustFragment(t, `<div id="main"  class="a b"><!-- c -->  a  <b>b</b> </div>`).HTMLWithOptions(
+		RenderOptions{CollapseWhitespace: true, OmitAttrQuotes: true},
+	); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+}
+
+func TestHTMLWithOptionsOptionalTags(t *testing.T) {
+	for _, tc := range []struct{ html, expect string }{
+		{`<div><p>a</p><div>b</div></div>`, `<div><p>a<div>b</div></div>`},
+		{`<div><p>a</p>b</div>`, `<div><p>a</p>b</div>`},
+		{`<a href=x><p>a</p></a>`, `<a href=x><p>a</p></a>`},
+		{`<dl><dt>a</dt><dd>b</dd><dt>c</dt><dd>d</dd></dl>`, `<dl><dt>a<dd>b<dt>c<dd>d</dl>`},
+		{`<select><option>a</option><optgroup><option>b</option></optgroup></select>`, `<select><option>a<optgroup><option>b</select>`},
+		{`<div> <span>a</span> <span>b</span> </div>`, `<div><span>a</span> <span>b</span></div>`},
+		{"<div><pre>\n\nx</pre></div>", "<div><pre>\n\nx</pre></div>"},
+		{`<div hidden="until-found"><input readonly="READONLY" required="no"></div>`, `<div hidden=until-found><input readonly required=no></div>`},
+	} {
+		if got := mustFragment(t, tc.html).HTMLWithOptions(MinifyOptions); got != tc.expect {
+			t.Errorf("expected %q; got %q", tc.expect, got)
+		}
+	}
+}
+
+// mustFragment returns the first node in the body of the HTML document parsed from s.
+func mustFragment(t *testing.T, s string) Node {
+	t.Helper()
+	doc, err := ParseHTMLDocument(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Find(Descendant, Body).FirstChild()
+}
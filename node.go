@@ -3,15 +3,18 @@ package node
 import (
 	"io"
 	"iter"
+	"net/url"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
 var (
-	_ HtmlNode = &htmlNode{}
-	_ Node     = &node{}
-	_ TextNode = &textNode{}
+	_ HtmlNode    = &htmlNode{}
+	_ Node        = &node{}
+	_ TextNode    = &textNode{}
+	_ CommentNode = &commentNode{}
+	_ DoctypeNode = &doctypeNode{}
 )
 
 // Node is an interface representing an HTML node.
@@ -30,6 +33,23 @@ type Node interface {
 
 	// GetText concatenates all of the text node's content.
 	GetText() string
+
+	// GetTextWithOptions is like GetText, with options for separator, stripping, whitespace collapsing
+	// and block-level line breaks.
+	GetTextWithOptions(TextOptions) string
+
+	// VisibleStrings is like Strings, but skips text inside elements matched by any of the exclude
+	// filters (InvisibleTags if none is given) and inside elements hidden by the hidden attribute,
+	// aria-hidden="true" or an inline display:none style.
+	VisibleStrings(exclude ...TagFilter) []TextNode
+
+	// VisibleText concatenates the content of the text nodes returned by VisibleStrings.
+	VisibleText(exclude ...TagFilter) string
+
+	// Links returns the URLs referenced by this node and its descendants from a, area, link, img,
+	// source, script and iframe elements, including each srcset candidate, in document order.
+	// URLs are resolved against the document's <base> element and the page URL, which may be nil.
+	Links(page *url.URL) []Link
 }
 
 // TextNode is an interface representing a text node.
@@ -40,19 +60,49 @@ type TextNode interface {
 	String() string
 }
 
+// CommentNode is an interface representing a comment node.
+type CommentNode interface {
+	HtmlNode
+
+	// String returns content for comment node.
+	String() string
+	// CDATA returns the content of a CDATA section and true if the comment is one.
+	// Outside of SVG and MathML, the HTML parser turns <![CDATA[...]]> into such a comment.
+	CDATA() (string, bool)
+}
+
+// DoctypeNode is an interface representing a document type declaration node.
+type DoctypeNode interface {
+	HtmlNode
+
+	// Name returns the name of the document type, e.g. "html".
+	Name() string
+	// PublicID returns the public identifier, or an empty string if there is none.
+	PublicID() string
+	// SystemID returns the system identifier, or an empty string if there is none.
+	SystemID() string
+}
+
 // HtmlNode is an interface representing an HTML node.
 type HtmlNode interface {
 	// Raw returns origin *html.Node.
+	// An Index covering the node does not see nodes added through it until invalidated.
 	Raw() *html.Node
 	// ToNode converts HtmlNode to Node.
 	ToNode() Node
 	// ToTextNode converts HtmlNode to TextNode.
 	// It will panic if the node type is not text node.
 	ToTextNode() TextNode
+	// ToCommentNode converts HtmlNode to CommentNode.
+	// It will panic if the node type is not comment node.
+	ToCommentNode() CommentNode
+	// ToDoctypeNode converts HtmlNode to DoctypeNode.
+	// It will panic if the node type is not doctype node.
+	ToDoctypeNode() DoctypeNode
 
 	// Type returns a NodeType.
 	Type() html.NodeType
-	// Data returns tag name for element node or content for text node.
+	// Data returns tag name for element node, content for text and comment node or name for doctype node.
 	Data() string
 	// Attrs returns an Attributes interface for element node.
 	Attrs() Attributes
@@ -60,8 +110,13 @@ type HtmlNode interface {
 	HasAttr(string) bool
 	// HTML renders the node's parse tree as HTML code.
 	HTML() string
+	// HTMLWithOptions is like HTML, with options for minified output.
+	HTMLWithOptions(RenderOptions) string
 	// Readable renders unescaped HTML code.
 	Readable() string
+	// Position returns where the node was found in the source and whether it is known.
+	// Positions are only recorded for documents parsed by ParseWithPositions.
+	Position() (Position, bool)
 
 	// Parent returns the parent of this node.
 	Parent() Node
@@ -145,6 +200,20 @@ func (n *htmlNode) ToTextNode() TextNode {
 	return &textNode{n}
 }
 
+func (n *htmlNode) ToCommentNode() CommentNode {
+	if n.Type() != html.CommentNode {
+		panic("node is not CommentNode")
+	}
+	return &commentNode{n}
+}
+
+func (n *htmlNode) ToDoctypeNode() DoctypeNode {
+	if n.Type() != html.DoctypeNode {
+		panic("node is not DoctypeNode")
+	}
+	return &doctypeNode{n}
+}
+
 func (n *htmlNode) Type() html.NodeType {
 	return n.Raw().Type
 }
@@ -154,17 +223,11 @@ func (n *htmlNode) Data() string {
 }
 
 func (n *htmlNode) Attrs() Attributes {
-	attrs := make(attributes)
-	for _, i := range n.Node.Attr {
-		if _, ok := attrs[i.Key]; !ok {
-			attrs[i.Key] = i.Val
-		}
-	}
-	return attrs
+	return attributes(n.Node.Attr)
 }
 
 func (n *htmlNode) HasAttr(attr string) bool {
-	_, ok := n.Attrs().Get(attr)
+	_, ok := getAttribute(n, attr)
 	return ok
 }
 
@@ -370,3 +433,36 @@ type textNode struct {
 func (n *textNode) String() string {
 	return n.Data()
 }
+
+type commentNode struct {
+	*htmlNode
+}
+
+func (n *commentNode) String() string {
+	return n.Data()
+}
+
+func (n *commentNode) CDATA() (string, bool) {
+	if s := n.Data(); strings.HasPrefix(s, "[CDATA[") && strings.HasSuffix(s, "]]") {
+		return s[len("[CDATA[") : len(s)-len("]]")], true
+	}
+	return "", false
+}
+
+type doctypeNode struct {
+	*htmlNode
+}
+
+func (n *doctypeNode) Name() string {
+	return n.Data()
+}
+
+func (n *doctypeNode) PublicID() string {
+	id, _ := n.Attrs().Get("public")
+	return id
+}
+
+func (n *doctypeNode) SystemID() string {
+	id, _ := n.Attrs().Get("system")
+	return id
+}
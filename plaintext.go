@@ -0,0 +1,371 @@
+package node
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// LinkStyle controls how PlainText renders links.
+type LinkStyle int
+
+const (
+	// LinkInline renders a link as its text followed by the URL in parentheses.
+	LinkInline LinkStyle = iota
+
+	// LinkFootnote renders a link as its text followed by a footnote number,
+	// and lists the URLs at the end of the text.
+	LinkFootnote
+
+	// LinkTextOnly renders only the text of a link.
+	LinkTextOnly
+)
+
+// PlainTextOptions controls the layout of PlainText.
+type PlainTextOptions struct {
+	// Width is the maximum line width in characters at which paragraphs are wrapped.
+	// Zero disables wrapping. Preformatted text and tables are never wrapped.
+	Width int
+	// Links is the style used to render links.
+	Links LinkStyle
+}
+
+// PlainText renders the node as plain text with a layout resembling the rendered page:
+// paragraphs are separated by blank lines, headings are underlined, lists are bulleted or numbered,
+// table columns are aligned, <pre> text is preserved and links are rendered according to the options.
+// Invisible and hidden elements are skipped.
+func PlainText(n HtmlNode, opts PlainTextOptions) string {
+	r := &textRenderer{opts: opts, footnotes: make(map[string]int)}
+	var lines []string
+	if n.Type() == html.ElementNode && n.Data() != "body" && n.Data() != "html" && isBlockElement(n.Raw()) {
+		lines, _ = r.element(n.Raw(), opts.Width)
+		lines = trimBlankLines(lines)
+	} else if n.Type() == html.TextNode {
+		lines = r.paragraph(n.Data(), opts.Width)
+	} else if n.Type() == html.ElementNode && !isBlockElement(n.Raw()) {
+		lines = r.paragraph(r.inline(n.Raw()), opts.Width)
+	} else {
+		lines = r.block(n.Raw(), opts.Width)
+	}
+	if len(r.links) > 0 && opts.Links == LinkFootnote {
+		lines = append(lines, "")
+		for i, link := range r.links {
+			lines = append(lines, "["+strconv.Itoa(i+1)+"] "+link)
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineBreak marks a <br> in inline text. The parser never produces NUL in text nodes.
+const lineBreak = "\x00"
+
+type textRenderer struct {
+	opts      PlainTextOptions
+	links     []string
+	footnotes map[string]int
+}
+
+// skip reports whether the element is not rendered.
+func skip(n *html.Node) bool {
+	return n.Type == html.ElementNode && (n.Data == "head" || isHidden(n) || InvisibleTags.IsMatch(NewNode(n)))
+}
+
+// spacedElements are separated from other blocks by a blank line.
+var spacedElements = map[string]bool{
+	"blockquote": true, "dl": true, "figure": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "hr": true, "ol": true, "p": true, "pre": true, "table": true, "ul": true,
+}
+
+// block renders the children of n as lines of at most width characters.
+func (r *textRenderer) block(n *html.Node, width int) (lines []string) {
+	var para strings.Builder
+	var spaced bool
+	add := func(block []string, blockSpaced bool) {
+		if block = trimBlankLines(block); len(block) == 0 {
+			return
+		}
+		if len(lines) > 0 && (spaced || blockSpaced) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, block...)
+		spaced = blockSpaced
+	}
+	flush := func() {
+		add(r.paragraph(para.String(), width), false)
+		para.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			para.WriteString(c.Data)
+		case c.Type != html.ElementNode || skip(c):
+		case isBlockElement(c) || c.Data == "li" || c.Data == "dd":
+			flush()
+			sub, subSpaced := r.element(c, width)
+			// Nested lists stay tight within their item.
+			add(sub, subSpaced && !(n.Data == "li" && (c.Data == "ul" || c.Data == "ol")))
+		default:
+			para.WriteString(r.inline(c))
+		}
+	}
+	flush()
+	return
+}
+
+// trimBlankLines removes the blank lines at the end of a block, such as those of an empty table
+// or of whitespace at the end of preformatted text.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// element renders a block-level element and reports whether it should be separated by blank lines.
+func (r *textRenderer) element(n *html.Node, width int) ([]string, bool) {
+	spaced := spacedElements[n.Data]
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		lines := r.paragraph(r.inline(n), width)
+		if len(lines) == 0 {
+			return nil, false
+		}
+		underline := "-"
+		if n.Data == "h1" {
+			underline = "="
+		}
+		var l int
+		for _, line := range lines {
+			l = max(l, utf8.RuneCountInString(line))
+		}
+		return append(lines, strings.Repeat(underline, l)), spaced
+	case "pre":
+		var b strings.Builder
+		for c := range n.Descendants() {
+			if c.Type == html.TextNode {
+				b.WriteString(c.Data)
+			} else if c.Type == html.ElementNode && c.Data == "br" {
+				b.WriteByte('\n')
+			}
+		}
+		return strings.Split(strings.TrimRight(b.String(), "\n"), "\n"), spaced
+	case "ul", "ol", "menu":
+		return r.list(n, width), spaced
+	case "table":
+		return r.table(n), spaced
+	case "blockquote":
+		return prefix(r.block(n, shrink(width, 2)), "> ", "> "), spaced
+	case "dd":
+		return prefix(r.block(n, shrink(width, 4)), "    ", "    "), spaced
+	case "hr":
+		l := 40
+		if r.opts.Width > 0 {
+			l = min(l, width)
+		}
+		return []string{strings.Repeat("-", l)}, spaced
+	}
+	return r.block(n, width), spaced
+}
+
+// list renders the items of a ul or ol element.
+func (r *textRenderer) list(n *html.Node, width int) (lines []string) {
+	ordered := n.Data == "ol"
+	i := 1
+	if start, err := attributes(n.Attr).Int("start"); err == nil && ordered {
+		i = start
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" || skip(c) {
+			continue
+		}
+		marker := "* "
+		if ordered {
+			marker = strconv.Itoa(i) + ". "
+			i++
+		}
+		indent := strings.Repeat(" ", utf8.RuneCountInString(marker))
+		item := r.block(c, shrink(width, len(indent)))
+		if len(item) == 0 {
+			item = []string{""}
+		}
+		lines = append(lines, prefix(item, marker, indent)...)
+	}
+	return
+}
+
+// table renders a table with aligned columns.
+func (r *textRenderer) table(n *html.Node) (lines []string) {
+	var rows [][]string
+	var header int // number of leading header rows
+	var rowsOf func(*html.Node)
+	rowsOf = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || skip(c) {
+				continue
+			}
+			switch c.Data {
+			case "thead", "tbody", "tfoot":
+				rowsOf(c)
+			case "tr":
+				var row []string
+				isHeader := true
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						row = append(row, strings.Join(strings.Fields(strings.ReplaceAll(r.inline(cell), lineBreak, " ")), " "))
+						isHeader = isHeader && (cell.Data == "th" || n.Data == "thead")
+					}
+				}
+				if isHeader && len(row) > 0 && len(rows) == header {
+					header++
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	rowsOf(n)
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for i, row := range rows {
+		var cells []string
+		for j := range widths {
+			var cell string
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells = append(cells, cell+strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)))
+		}
+		lines = append(lines, strings.Join(cells, " | "))
+		if i == header-1 && header < len(rows) {
+			var sep []string
+			for _, w := range widths {
+				sep = append(sep, strings.Repeat("-", w))
+			}
+			lines = append(lines, strings.Join(sep, "-+-"))
+		}
+	}
+	return
+}
+
+// inline renders the content of an inline element as text.
+// Line breaks are marked with lineBreak and whitespace is collapsed later.
+func (r *textRenderer) inline(n *html.Node) string {
+	switch n.Data {
+	case "br":
+		return lineBreak
+	case "img":
+		if alt, ok := attributes(n.Attr).Get("alt"); ok {
+			return alt
+		}
+		return ""
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type != html.ElementNode || skip(c):
+		case isBlockElement(c):
+			b.WriteString(" " + r.inline(c) + " ")
+		default:
+			b.WriteString(r.inline(c))
+		}
+	}
+	s := b.String()
+	if n.Data == "a" {
+		href, _ := attributes(n.Attr).Get("href")
+		href = strings.TrimSpace(href)
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return s
+		}
+		switch r.opts.Links {
+		case LinkInline:
+			if strings.TrimSpace(s) != href {
+				s += " (" + href + ")"
+			}
+		case LinkFootnote:
+			i, ok := r.footnotes[href]
+			if !ok {
+				r.links = append(r.links, href)
+				i = len(r.links)
+				r.footnotes[href] = i
+			}
+			s += "[" + strconv.Itoa(i) + "]"
+		}
+	}
+	return s
+}
+
+// paragraph collapses whitespace in s and wraps it to width, keeping line breaks.
+func (r *textRenderer) paragraph(s string, width int) (lines []string) {
+	var words [][]string
+	for segment := range strings.SplitSeq(s, lineBreak) {
+		words = append(words, strings.Fields(segment))
+	}
+	// Drop leading and trailing empty segments.
+	for len(words) > 0 && len(words[0]) == 0 {
+		words = words[1:]
+	}
+	for len(words) > 0 && len(words[len(words)-1]) == 0 {
+		words = words[:len(words)-1]
+	}
+	for _, w := range words {
+		lines = append(lines, wrap(w, width)...)
+	}
+	return
+}
+
+// wrap joins words into lines of at most width characters. Zero width disables wrapping.
+func wrap(words []string, width int) (lines []string) {
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var line strings.Builder
+	var l int
+	for _, w := range words {
+		wl := utf8.RuneCountInString(w)
+		if l > 0 && width > 0 && l+1+wl > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			l = 0
+		}
+		if l > 0 {
+			line.WriteByte(' ')
+			l++
+		}
+		line.WriteString(w)
+		l += wl
+	}
+	return append(lines, line.String())
+}
+
+// prefix prefixes the first line with first and the others with rest.
+func prefix(lines []string, first, rest string) []string {
+	for i := range lines {
+		if i == 0 {
+			lines[i] = first + lines[i]
+		} else if lines[i] != "" || rest != strings.Repeat(" ", len(rest)) {
+			lines[i] = rest + lines[i]
+		}
+	}
+	return lines
+}
+
+// shrink reduces width by n, keeping zero (no wrapping) as is.
+func shrink(width, n int) int {
+	if width == 0 {
+		return 0
+	}
+	return max(width-n, 1)
+}
@@ -0,0 +1,83 @@
+package node
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	doc, err := ParseHTML(`<html><head><title>ignored</title></head><body>
+<h1>Weekly   news</h1>
+<p>Read the <a href="https://example.com/a">first story</a> and
+the <a href="https://example.com/b">second one</a>.</p>
+<ul><li>apples</li><li>pears<ol start="3"><li>nested</li></ol></li></ul>
+<table><thead><tr><th>Name</th><th>Qty</th></tr></thead>
+<tbody><tr><td>apple</td><td>10</td></tr><tr><td>kiwi</td><td>2</td></tr></tbody></table>
+<pre>  keep
+    this</pre>
+<blockquote>quoted<br>text</blockquote>
+<script>ignored()</script><p hidden>ignored</p>
+</body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Weekly news
+===========
+
+Read the first story (https://example.com/a) and the second one
+(https://example.com/b).
+
+* apples
+* pears
+  3. nested
+
+Name  | Qty
+------+----
+apple | 10
+kiwi  | 2
+
+  keep
+    this
+
+> quoted
+> text`
+	if text := PlainText(doc, PlainTextOptions{Width: 64}); text != expected {
+		t.Errorf("expected text\n%s\ngot\n%s", expected, text)
+	}
+
+	expected = `Read the first story[1] and the second one[2].
+
+[1] https://example.com/a
+[2] https://example.com/b`
+	if text := PlainText(doc.Find(0, P), PlainTextOptions{Links: LinkFootnote}); text != expected {
+		t.Errorf("expected text\n%s\ngot\n%s", expected, text)
+	}
+	if text := PlainText(doc.Find(0, A), PlainTextOptions{Links: LinkTextOnly}); text != "first story" {
+		t.Errorf("expected text %q; got %q", "first story", text)
+	}
+}
+
+func TestPlainTextTableEmptyRow(t *testing.T) {
+	doc, err := ParseHTML(`<table><tr><th>Name</th></tr><tr></tr><tr><td>apple</td></tr></table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Name\n-----\n\napple"
+	if text := PlainText(doc, PlainTextOptions{}); text != expected {
+		t.Errorf("expected text %q; got %q", expected, text)
+	}
+}
+
+func TestPlainTextBlankBlocks(t *testing.T) {
+	for _, tc := range []struct{ html, expected string }{
+		{"<p>a</p><table><tr></tr></table><p>b</p>", "a\n\nb"},
+		{"<p>a</p><table><tr><td> </td></tr></table><p>b</p>", "a\n\nb"},
+		{"<p>a</p><pre>code\n  \n</pre><p>b</p>", "a\n\ncode\n\nb"},
+		{"<p>a</p><pre>\n\n</pre><p>b</p>", "a\n\nb"},
+	} {
+		doc, err := ParseHTML(tc.html)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := PlainText(doc, PlainTextOptions{}); text != tc.expected {
+			t.Errorf("%q: expected text %q; got %q", tc.html, tc.expected, text)
+		}
+	}
+}
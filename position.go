@@ -0,0 +1,266 @@
+package node
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"weak"
+
+	"golang.org/x/net/html"
+)
+
+var sources sync.Map // map[weak.Pointer[html.Node]]*sourceMap
+
+// Position describes where a node was found in the parsed source.
+// Offsets are in bytes, lines and columns are 1-based and columns count characters.
+type Position struct {
+	Start, End         int // byte offsets of the node, End is exclusive
+	Line, Column       int // location of Start
+	EndLine, EndColumn int // location of End
+}
+
+// sourceMap records the source of a document and the positions of its nodes.
+// Nodes are held weakly so that the map does not keep the document alive.
+type sourceMap struct {
+	src   []byte
+	lines []int // byte offset of the start of each line
+	pos   map[weak.Pointer[html.Node]]Position
+}
+
+// ParseWithPositions returns the Document for the HTML from the given Reader,
+// recording the source position of each element, text and comment node,
+// which is then available from HtmlNode.Position and Document.Source.
+//
+// Positions are recovered by matching the parse tree against the tokens of the source,
+// so elements created by the parser, such as a missing <tbody> or the clones of misnested
+// formatting elements, have no position, and elements moved by error recovery may not have one either.
+func ParseWithPositions(r io.Reader) (*Document, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := html.Parse(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	sm := newSourceMap(src, n)
+	key := weak.Make(n)
+	sources.Store(key, sm)
+	runtime.AddCleanup(n, func(key weak.Pointer[html.Node]) { sources.Delete(key) }, key)
+	doc := newDocument(NewNode(n), "utf-8")
+	doc.source = sm
+	return doc, nil
+}
+
+// Source returns the part of the original source the node was parsed from,
+// or an empty string if the node has no recorded position.
+func (d *Document) Source(n HtmlNode) string {
+	if d.source == nil {
+		return ""
+	}
+	pos, ok := d.source.pos[weak.Make(n.Raw())]
+	if !ok {
+		return ""
+	}
+	return string(d.source.src[pos.Start:pos.End])
+}
+
+func (n *htmlNode) Position() (Position, bool) {
+	root := n.Node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	sm, ok := sources.Load(weak.Make(root))
+	if !ok {
+		return Position{}, false
+	}
+	pos, ok := sm.(*sourceMap).pos[weak.Make(n.Node)]
+	return pos, ok
+}
+
+type sourceToken struct {
+	typ        html.TokenType
+	name, data string
+	raw        string
+	start, end int
+	used       bool
+}
+
+// impliedTags are elements the parser may create without a start tag.
+// They only match the very next start tag, so that an implied element
+// never steals the tag of a later explicit one.
+var impliedTags = map[string]bool{
+	"html": true, "head": true, "body": true, "tbody": true, "tr": true, "colgroup": true,
+}
+
+// formattingTags are the formatting elements whose misnested end tags the parser handles with the
+// adoption agency algorithm, which closes the formatting element but leaves the blocks inside it open.
+var formattingTags = map[string]bool{
+	"a": true, "b": true, "big": true, "code": true, "em": true, "font": true, "i": true, "nobr": true,
+	"s": true, "small": true, "strike": true, "strong": true, "tt": true, "u": true,
+}
+
+// autoClose lists start tags which implicitly close an open element.
+var autoClose = map[string][]string{
+	"li": {"li"}, "dt": {"dt", "dd"}, "dd": {"dt", "dd"}, "p": {"p"}, "option": {"option"},
+	"tr": {"tr", "td", "th"}, "td": {"td", "th"}, "th": {"td", "th"},
+	"thead": {"tbody", "tfoot"}, "tbody": {"thead", "tbody", "tfoot"}, "tfoot": {"thead", "tbody"},
+}
+
+const positionWindow = 16
+
+func newSourceMap(src []byte, root *html.Node) *sourceMap {
+	sm := &sourceMap{src: src, lines: []int{0}, pos: make(map[weak.Pointer[html.Node]]Position)}
+	for i, c := range src {
+		if c == '\n' {
+			sm.lines = append(sm.lines, i+1)
+		}
+	}
+
+	var tags, texts, comments []*sourceToken
+	var stack []*sourceToken
+	closeTo := func(i int, end int) {
+		for _, t := range stack[i:] {
+			t.end = end
+		}
+		stack = stack[:i]
+	}
+	z := html.NewTokenizer(bytes.NewReader(src))
+	for offset := 0; ; {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+		tok := z.Token()
+		t := &sourceToken{typ: tt, name: tok.Data, data: tok.Data, raw: raw, start: offset, end: offset + len(raw)}
+		offset = t.end
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tags = append(tags, t)
+			if closes, ok := autoClose[t.name]; ok && len(stack) > 0 {
+				for _, name := range closes {
+					if stack[len(stack)-1].name == name {
+						closeTo(len(stack)-1, t.start)
+						break
+					}
+				}
+			}
+			if tt == html.StartTagToken && !isVoidElement(t.name) {
+				stack = append(stack, t)
+			}
+		case html.EndTagToken:
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == t.name {
+					stack[i].end = t.end
+					if formattingTags[t.name] {
+						stack = append(stack[:i], stack[i+1:]...)
+					} else {
+						closeTo(i+1, t.start)
+						stack = stack[:i]
+					}
+					break
+				}
+			}
+		case html.TextToken:
+			texts = append(texts, t)
+		case html.CommentToken:
+			comments = append(comments, t)
+		}
+	}
+	closeTo(0, len(src))
+
+	// Each node only matches tokens within the source range of its nearest matched ancestor,
+	// so that elements created by the parser, such as the clones of misnested formatting
+	// elements, do not take the tokens of later elements.
+	matched := make(map[*html.Node]*sourceToken)
+	within := func(n *html.Node) func(*sourceToken) bool {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if impliedTags[p.Data] {
+				continue
+			}
+			if pt, ok := matched[p]; ok {
+				return func(t *sourceToken) bool { return t.start > pt.start && t.start < pt.end }
+			}
+		}
+		return func(*sourceToken) bool { return true }
+	}
+	var tagCursor, textCursor, commentCursor int
+	for n := range root.Descendants() {
+		var t *sourceToken
+		inParent := within(n)
+		switch n.Type {
+		case html.ElementNode:
+			window := positionWindow
+			if impliedTags[n.Data] {
+				window = 1
+			}
+			// Tag names are lowercased by the tokenizer but not for SVG elements such as foreignObject.
+			t = match(tags, &tagCursor, window, func(t *sourceToken) bool { return inParent(t) && strings.EqualFold(t.name, n.Data) })
+			if t != nil {
+				matched[n] = t
+			}
+		case html.TextNode:
+			t = match(texts, &textCursor, positionWindow, func(t *sourceToken) bool {
+				// The parser drops the leading newline of <pre>, <listing> and <textarea>.
+				return inParent(t) && (t.data == n.Data || t.data == "\n"+n.Data)
+			})
+			if t != nil && t.data != n.Data && strings.HasPrefix(t.raw, "\n") {
+				sm.pos[weak.Make(n)] = sm.position(t.start+1, t.end)
+				continue
+			}
+		case html.CommentNode:
+			t = match(comments, &commentCursor, positionWindow, func(t *sourceToken) bool { return inParent(t) && t.data == n.Data })
+		}
+		if t != nil {
+			sm.pos[weak.Make(n)] = sm.position(t.start, t.end)
+		}
+	}
+	return sm
+}
+
+// match returns the first unused token within window tokens from the cursor which satisfies f
+// and marks it as used. Whitespace text tokens, which the parser often drops, are not
+// counted towards the window. Tokens left far behind the match are abandoned.
+func match(tokens []*sourceToken, cursor *int, window int, f func(*sourceToken) bool) *sourceToken {
+	for i, seen := *cursor, 0; i < len(tokens) && seen < window; i++ {
+		t := tokens[i]
+		if t.used {
+			continue
+		}
+		if t.typ != html.TextToken || strings.TrimSpace(t.data) != "" {
+			seen++
+		}
+		if f(t) {
+			t.used = true
+			for *cursor < len(tokens) && (tokens[*cursor].used || *cursor < i-2*window) {
+				*cursor++
+			}
+			return t
+		}
+	}
+	return nil
+}
+
+func (sm *sourceMap) position(start, end int) Position {
+	line, column := sm.location(start)
+	endLine, endColumn := sm.location(end)
+	return Position{start, end, line, column, endLine, endColumn}
+}
+
+func (sm *sourceMap) location(offset int) (line, column int) {
+	i := sort.Search(len(sm.lines), func(i int) bool { return sm.lines[i] > offset }) - 1
+	return i + 1, utf8.RuneCount(sm.src[sm.lines[i]:offset]) + 1
+}
+
+func isVoidElement(name string) bool {
+	switch name {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
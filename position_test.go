@@ -0,0 +1,101 @@
+package node
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWithPositions(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html>
+<head><title>Title</title></head>
+<body>
+<ul>
+  <li class="a">first
+  <li class="b">второй</li>
+</ul>
+<!-- note -->
+<table><tr><td>cell</td></tr></table>
+<pre>
+code</pre>
+</body>
+</html>`
+	doc, err := ParseWithPositions(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		node   HtmlNode
+		source string
+		line   int
+		column int
+	}{
+		{doc.Find(0, Title), "<title>Title</title>", 3, 7},
+		{doc.Find(0, Ul), "<ul>\n  <li class=\"a\">first\n  <li class=\"b\">второй</li>\n</ul>", 5, 1},
+		{doc.Find(0, Li, Class("a")), "<li class=\"a\">first\n  ", 6, 3},
+		{doc.Find(0, Li, Class("b")), "<li class=\"b\">второй</li>", 7, 3},
+		{doc.FindString(0, String("второй")), "второй", 7, 17},
+		{doc.Find(0, Td), "<td>cell</td>", 10, 12},
+		{doc.FindString(0, String("code")), "code", 12, 1},
+	} {
+		pos, ok := tc.node.Position()
+		if !ok {
+			t.Errorf("%q: expected position", tc.source)
+			continue
+		}
+		if pos.Line != tc.line || pos.Column != tc.column {
+			t.Errorf("%q: expected %d:%d; got %d:%d", tc.source, tc.line, tc.column, pos.Line, pos.Column)
+		}
+		if s := doc.Source(tc.node); s != tc.source {
+			t.Errorf("expected source %q; got %q", tc.source, s)
+		}
+	}
+	if _, ok := doc.Find(0, Tag("tbody")).Position(); ok {
+		t.Error("expected no position for implied tbody")
+	}
+	if s := doc.Source(doc.FindComment(0, True)); s != "<!-- note -->" {
+		t.Errorf("expected source %q; got %q", "<!-- note -->", s)
+	}
+	if pos, ok := doc.Find(0, Tag("html")).Position(); !ok || pos.End != len(src) {
+		t.Errorf("expected html to end at %d; got %v", len(src), pos)
+	}
+	if _, ok := soup.Find(0, Title).Position(); ok {
+		t.Error("expected no position without ParseWithPositions")
+	}
+}
+
+func TestParseWithPositionsMisnested(t *testing.T) {
+	src := `<p><b>1<div>2</b>3</div><b>4</b></p><svg><foreignObject><clipPath id="c"></clipPath></foreignObject></svg>`
+	doc, err := ParseWithPositions(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := doc.FindAll(0, B)
+	if len(bs) != 3 {
+		t.Fatalf("expected b elements %d; got %d", 3, len(bs))
+	}
+	if s := doc.Source(bs[0]); s != "<b>1<div>2</b>" {
+		t.Errorf("expected source %q; got %q", "<b>1<div>2</b>", s)
+	}
+	if _, ok := bs[1].Position(); ok {
+		t.Errorf("expected no position for the b cloned by the parser; got %q", doc.Source(bs[1]))
+	}
+	if s := doc.Source(bs[2]); s != "<b>4</b>" {
+		t.Errorf("expected source %q; got %q", "<b>4</b>", s)
+	}
+	if s := doc.Source(doc.FindString(0, String("3"))); s != "3" {
+		t.Errorf("expected source %q; got %q", "3", s)
+	}
+	foreignObject := doc.Find(0, Tag("svg")).FirstChild()
+	for _, tc := range []struct {
+		node   Node
+		source string
+	}{
+		{foreignObject, `<foreignObject><clipPath id="c"></clipPath></foreignObject>`},
+		{foreignObject.FirstChild(), `<clipPath id="c"></clipPath>`},
+	} {
+		if s := doc.Source(tc.node); s != tc.source {
+			t.Errorf("%s: expected source %q; got %q", tc.node.Data(), tc.source, s)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// URLRewrite records an attribute changed by RewriteURLs.
+type URLRewrite struct {
+	Node     Node
+	Attr     string
+	Old, New string // attribute values before and after
+}
+
+// urlAttrs are the attributes rewritten by RewriteURLs, on any element.
+var urlAttrs = map[string]bool{"href": true, "src": true, "srcset": true, "action": true, "poster": true, "style": true}
+
+// cssURL matches url() in CSS, capturing the double-quoted, single-quoted or unquoted reference.
+var cssURL = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^\s"'()]*))\s*\)`)
+
+// RewriteURLs replaces each URL in the href, src, srcset, action and poster attributes and
+// in the url() functions of style attributes of n and its descendants with the result of rewrite,
+// which is called with the element, the attribute name and the URL as written.
+// Empty URLs are left unchanged, and only the URLs of a srcset are replaced, keeping its descriptors as written.
+// It returns the changed attributes in document order.
+func RewriteURLs(n HtmlNode, rewrite func(n Node, attr, ref string) string) (changes []URLRewrite) {
+	rewriteElement := func(e *html.Node) {
+		if e.Type != html.ElementNode {
+			return
+		}
+		var node Node
+		for i, a := range e.Attr {
+			if a.Namespace != "" || !urlAttrs[a.Key] {
+				continue
+			}
+			if node == nil {
+				node = NewNode(e)
+			}
+			var v string
+			switch a.Key {
+			case "srcset":
+				v = rewriteSrcset(a.Val, func(ref string) string { return rewrite(node, a.Key, ref) })
+			case "style":
+				v = rewriteCSSURLs(a.Val, func(ref string) string { return rewrite(node, a.Key, ref) })
+			default:
+				if ref := strings.TrimSpace(a.Val); ref != "" {
+					v = rewrite(node, a.Key, ref)
+				} else {
+					v = a.Val
+				}
+			}
+			if v != a.Val {
+				e.Attr[i].Val = v
+				changes = append(changes, URLRewrite{node, a.Key, a.Val, v})
+			}
+		}
+	}
+	rewriteElement(n.Raw())
+	for c := range n.Raw().Descendants() {
+		rewriteElement(c)
+	}
+	return
+}
+
+// rewriteSrcset replaces the URLs of a srcset attribute, leaving the rest of it as written.
+func rewriteSrcset(s string, rewrite func(string) string) string {
+	var b strings.Builder
+	var last int
+	for _, c := range scanSrcset(s) {
+		b.WriteString(s[last:c.start])
+		b.WriteString(rewrite(s[c.start:c.end]))
+		last = c.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func rewriteCSSURLs(s string, rewrite func(string) string) string {
+	var b strings.Builder
+	var last int
+	for _, m := range cssURL.FindAllStringSubmatchIndex(s, -1) {
+		for g := 1; g <= 3; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 || start == end {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(rewrite(s[start:end]))
+			last = end
+		}
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
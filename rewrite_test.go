@@ -0,0 +1,63 @@
+package node
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRewriteURLs(t *testing.T) {
+	doc, err := ParseHTML(`<a href="/about">about</a><a href="">empty</a>
+<img src="a.png" srcset="a.png 1x, b.png 2x">
+<form action="search"></form><video poster="p.jpg"></video>
+<div style="background: url('bg.png') no-repeat, url(&quot;x.png&quot;); color: red"></div>
+<a href="https://other.example.org/">other</a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://example.com/dir/")
+	var calls int
+	changes := RewriteURLs(doc, func(n Node, attr, ref string) string {
+		calls++
+		u, err := url.Parse(ref)
+		if err != nil {
+			return ref
+		}
+		return base.ResolveReference(u).String()
+	})
+	if calls != 9 {
+		t.Errorf("expected calls %d; got %d", 9, calls)
+	}
+	expected := []URLRewrite{
+		{nil, "href", "/about", "https://example.com/about"},
+		{nil, "src", "a.png", "https://example.com/dir/a.png"},
+		{nil, "srcset", "a.png 1x, b.png 2x", "https://example.com/dir/a.png 1x, https://example.com/dir/b.png 2x"},
+		{nil, "action", "search", "https://example.com/dir/search"},
+		{nil, "poster", "p.jpg", "https://example.com/dir/p.jpg"},
+		{nil, "style", `background: url('bg.png') no-repeat, url("x.png"); color: red`,
+			`background: url('https://example.com/dir/bg.png') no-repeat, url("https://example.com/dir/x.png"); color: red`},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected changes %d; got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, c := range changes {
+		if c.Attr != expected[i].Attr || c.Old != expected[i].Old || c.New != expected[i].New {
+			t.Errorf("expected change %v; got %v", expected[i], c)
+		}
+		if v, _ := c.Node.Attrs().Get(c.Attr); v != c.New {
+			t.Errorf("expected attribute %q; got %q", c.New, v)
+		}
+	}
+}
+
+func TestRewriteSrcset(t *testing.T) {
+	doc, err := ParseHTML(`<img srcset=" a.jpg 100w 50h,b.jpg 0x,
+	c.jpg,, d.jpg">`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	RewriteURLs(doc, func(_ Node, _, ref string) string { return "/m/" + ref })
+	expected := " /m/a.jpg 100w 50h,/m/b.jpg 0x,\n\t/m/c.jpg,, /m/d.jpg"
+	if v, _ := doc.Find(0, Img).Attrs().Get("srcset"); v != expected {
+		t.Errorf("expected srcset %q; got %q", expected, v)
+	}
+}
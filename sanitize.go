@@ -0,0 +1,159 @@
+package node
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Policy is an allow-list of the elements, attributes and URL schemes kept by Sanitize.
+type Policy struct {
+	// Elements maps each allowed element to the attributes allowed on it.
+	Elements map[string][]string
+	// GlobalAttrs are allowed on every allowed element.
+	GlobalAttrs []string
+	// URLSchemes are the schemes allowed in URL attributes. Relative URLs are always allowed.
+	URLSchemes []string
+	// LinkRel, if not empty, is set as the rel attribute of links with an href, e.g. "nofollow ugc".
+	LinkRel string
+}
+
+// StrictPolicy returns a policy which allows no elements, keeping only text.
+func StrictPolicy() *Policy {
+	return &Policy{Elements: map[string][]string{}}
+}
+
+// BasicPolicy returns a policy which allows basic text formatting, lists, quotes, code and links
+// with http, https and mailto URLs.
+func BasicPolicy() *Policy {
+	p := &Policy{Elements: make(map[string][]string), URLSchemes: []string{"http", "https", "mailto"}}
+	for _, tag := range []string{"b", "strong", "i", "em", "u", "s", "small", "mark", "sub", "sup", "br",
+		"p", "code", "pre", "ul", "ol", "li", "span"} {
+		p.Elements[tag] = nil
+	}
+	p.Elements["a"] = []string{"href", "title"}
+	p.Elements["blockquote"] = []string{"cite"}
+	return p
+}
+
+// UGCPolicy returns a policy for user generated content: BasicPolicy plus headings, images, tables
+// and other structural elements, with nofollow and ugc added to the rel of links.
+func UGCPolicy() *Policy {
+	p := BasicPolicy()
+	for _, tag := range []string{"h1", "h2", "h3", "h4", "h5", "h6", "hr", "div", "dl", "dt", "dd", "cite",
+		"figure", "figcaption", "caption", "thead", "tbody", "tfoot", "tr", "kbd", "samp", "var"} {
+		p.Elements[tag] = nil
+	}
+	p.Elements["img"] = []string{"src", "srcset", "alt", "title", "width", "height"}
+	p.Elements["table"] = []string{"summary"}
+	p.Elements["th"] = []string{"colspan", "rowspan", "scope"}
+	p.Elements["td"] = []string{"colspan", "rowspan"}
+	p.Elements["abbr"] = []string{"title"}
+	p.Elements["q"] = []string{"cite"}
+	p.Elements["del"] = []string{"cite", "datetime"}
+	p.Elements["ins"] = []string{"cite", "datetime"}
+	p.Elements["time"] = []string{"datetime"}
+	p.Elements["ol"] = []string{"start", "reversed", "type"}
+	p.GlobalAttrs = []string{"lang", "dir", "title"}
+	p.LinkRel = "nofollow ugc"
+	return p
+}
+
+// dropContent are disallowed elements removed with their content.
+// Other disallowed elements are replaced by their children.
+var dropContent = map[string]bool{
+	"script": true, "style": true, "template": true, "noscript": true, "iframe": true, "frame": true,
+	"frameset": true, "object": true, "embed": true, "applet": true, "head": true, "title": true,
+	"select": true, "textarea": true, "svg": true, "math": true,
+}
+
+// sanitizedURLAttrs hold URLs whose scheme must be allowed.
+var sanitizedURLAttrs = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true, "poster": true,
+	"background": true, "longdesc": true, "data": true, "srcset": true,
+}
+
+// Sanitize removes from the descendants of n everything the policy does not allow and returns
+// the inner HTML of n, which is kept itself. Disallowed elements are replaced by their content,
+// except scripts, styles, frames, SVG, MathML and the like, which are removed entirely.
+// Comments, event handler attributes and URLs with disallowed schemes, such as javascript:,
+// are always removed. The tree is modified in place.
+func Sanitize(n Node, p *Policy) string {
+	p.sanitizeChildren(n.Raw())
+	invalidateIndex(n.Raw())
+	return innerHTML(n.Raw())
+}
+
+func (p *Policy) sanitizeChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			attrs, allowed := p.Elements[c.Data]
+			if c.Namespace != "" || dropContent[c.Data] && !allowed {
+				n.RemoveChild(c)
+				break
+			}
+			p.sanitizeChildren(c)
+			if !allowed {
+				// Unwrap the element, keeping its sanitized children in place.
+				for gc := c.FirstChild; gc != nil; gc = c.FirstChild {
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+				}
+				n.RemoveChild(c)
+				break
+			}
+			p.sanitizeAttrs(c, attrs)
+		default:
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
+
+func (p *Policy) sanitizeAttrs(n *html.Node, allowed []string) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case a.Namespace != "", strings.HasPrefix(key, "on"):
+			continue
+		case !slices.Contains(allowed, key) && !slices.Contains(p.GlobalAttrs, key):
+			continue
+		case key == "srcset":
+			candidates, err := ParseSrcset(a.Val)
+			if err != nil || slices.ContainsFunc(candidates, func(c ImageCandidate) bool { return !p.allowURL(c.URL) }) {
+				continue
+			}
+		case sanitizedURLAttrs[key] && !p.allowURL(a.Val):
+			continue
+		case key == "rel" && p.LinkRel != "" && n.Data == "a":
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+	if p.LinkRel != "" && n.Data == "a" && attributes(n.Attr).has("href") {
+		n.Attr = append(n.Attr, html.Attribute{Key: "rel", Val: p.LinkRel})
+	}
+}
+
+// allowURL reports whether the URL is relative or has an allowed scheme.
+func (p *Policy) allowURL(s string) bool {
+	// Browsers ignore tabs and newlines in URLs, so "java\tscript:" is still a javascript: URL.
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || slices.Contains(p.URLSchemes, strings.ToLower(u.Scheme))
+}
@@ -0,0 +1,50 @@
+package node
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	const src = `<div id="post"><p onclick="steal()" class="x">Hello <b>world</b><script>alert(1)</script></p>` +
+		`<!-- note --><a href="javascript:alert(1)" target="_blank">bad</a> <a href=" JaVa&#9;script:alert(1)">tab</a> ` +
+		`<a href="https://example.com/" rel="opener">good</a> <a href="/local">local</a>` +
+		`<img src="data:image/png;base64,AAAA" alt="pic" onerror="x()"><img src="/a.png" srcset="/a.png 1x, javascript:x 2x">` +
+		`<h1 title="t" style="color:red">Title</h1><iframe src="https://evil.example"></iframe>` +
+		`<custom><i>kept</i></custom><svg><a href="/x">svg</a></svg></div>`
+
+	for _, tc := range []struct {
+		policy func() *Policy
+		want   string
+	}{
+		{StrictPolicy, `Hello worldbad tab good localTitlekept`},
+		{BasicPolicy, `<p>Hello <b>world</b></p><a>bad</a> <a>tab</a> <a href="https://example.com/">good</a> ` +
+			`<a href="/local">local</a>Title<i>kept</i>`},
+		{UGCPolicy, `<p>Hello <b>world</b></p><a>bad</a> <a>tab</a> <a href="https://example.com/" rel="nofollow ugc">good</a> ` +
+			`<a href="/local" rel="nofollow ugc">local</a><img alt="pic"/><img src="/a.png"/><h1 title="t">Title</h1><i>kept</i>`},
+	} {
+		doc, err := ParseHTMLDocument(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		div := doc.Find(Descendant, nil, Id("post"))
+		if got := Sanitize(div, tc.policy()); got != tc.want {
+			t.Errorf("expected %q; got %q", tc.want, got)
+		}
+		if div.Find(Descendant, Tag("script")) != nil {
+			t.Error("expected script removed; got script")
+		}
+	}
+}
+
+func TestSanitizeCustomPolicy(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<p><a href="ftp://example.com/f" onmouseover="x()">ftp</a> <span lang="en" class="c">x</span></p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Policy{
+		Elements:    map[string][]string{"a": {"href"}, "span": nil},
+		GlobalAttrs: []string{"lang"},
+		URLSchemes:  []string{"ftp"},
+	}
+	if expect, got := `<a href="ftp://example.com/f">ftp</a> <span lang="en">x</span>`, Sanitize(doc.Find(Descendant, P), p); got != expect {
+		t.Errorf("expected %q; got %q", expect, got)
+	}
+}
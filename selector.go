@@ -0,0 +1,89 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/ericchiang/css"
+)
+
+// Selector is a compiled query which selects nodes from the parse tree.
+// Unlike Select and XPath, compiling a selector reports invalid queries as errors instead of panicking.
+type Selector interface {
+	// SelectAll returns all nodes matched by the selector starting from n.
+	SelectAll(n HtmlNode) []Node
+	// String returns the query the selector was compiled from.
+	String() string
+}
+
+// CompileCSS compiles a css selector. As with Finder.SelectAll, the node itself can be matched.
+func CompileCSS(sel string) (Selector, error) {
+	s, err := css.Parse(sel)
+	if err != nil {
+		return nil, err
+	}
+	return &cssSelector{sel, s}, nil
+}
+
+// CompileXPath compiles an XPath expression which selects nodes.
+func CompileXPath(expr string) (Selector, error) {
+	exp, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &xpathSelector{expr, exp}, nil
+}
+
+// FindSelector returns a Selector which finds the descendants matched by the tag and filters like FindAll.
+func FindSelector(tag TagFilter, filters ...Filter) Selector {
+	return &findSelector{tag, filters}
+}
+
+type cssSelector struct {
+	src string
+	sel *css.Selector
+}
+
+func (s *cssSelector) SelectAll(n HtmlNode) (res []Node) {
+	if nodes, ok := (&htmlNode{n.Raw()}).selectIndexed(s.src); ok {
+		return nodes
+	}
+	for _, i := range s.sel.Select(n.Raw()) {
+		res = append(res, NewNode(i))
+	}
+	return
+}
+
+func (s *cssSelector) String() string {
+	return s.src
+}
+
+type xpathSelector struct {
+	src string
+	exp *xpath.Expr
+}
+
+func (s *xpathSelector) SelectAll(n HtmlNode) (res []Node) {
+	for _, i := range htmlquery.QuerySelectorAll(n.Raw(), s.exp) {
+		res = append(res, NewNode(i))
+	}
+	return
+}
+
+func (s *xpathSelector) String() string {
+	return s.src
+}
+
+type findSelector struct {
+	tag     TagFilter
+	filters []Filter
+}
+
+func (s *findSelector) SelectAll(n HtmlNode) []Node {
+	return n.FindAll(Descendant, s.tag, s.filters...)
+}
+
+func (s *findSelector) String() string {
+	return fmt.Sprintf("find(%v, %v)", s.tag, s.filters)
+}
@@ -0,0 +1,141 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageCandidate is a single image candidate of a srcset attribute.
+type ImageCandidate struct {
+	URL string
+	// Width is the width descriptor (e.g. 480w), or zero if absent.
+	Width int
+	// Density is the pixel density descriptor (e.g. 2x).
+	// It defaults to 1 when neither width nor density is given.
+	Density float64
+}
+
+// String returns the candidate in srcset syntax.
+func (c ImageCandidate) String() string {
+	switch {
+	case c.Width > 0:
+		return c.URL + " " + strconv.Itoa(c.Width) + "w"
+	case c.Density != 0 && c.Density != 1:
+		return c.URL + " " + strconv.FormatFloat(c.Density, 'f', -1, 64) + "x"
+	default:
+		return c.URL
+	}
+}
+
+// ParseSrcset parses a srcset attribute value into image candidates,
+// following the parsing rules of the HTML specification. Like browsers, it skips invalid
+// candidates and returns the valid ones, with an error reporting each invalid candidate.
+func ParseSrcset(s string) (candidates []ImageCandidate, err error) {
+	var errs []error
+	for _, sc := range scanSrcset(s) {
+		candidate := ImageCandidate{URL: s[sc.start:sc.end]}
+		if err := candidate.parseDescriptors(sc.descriptors); err != nil {
+			errs = append(errs, fmt.Errorf("candidate %q: %w", candidate.URL, err))
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if len(errs) > 0 {
+		err = fmt.Errorf("invalid srcset: %w", errors.Join(errs...))
+	}
+	return
+}
+
+// srcsetCandidate locates an image candidate within a srcset attribute.
+type srcsetCandidate struct {
+	start, end  int // byte offsets of the URL
+	descriptors []string
+}
+
+// scanSrcset splits a srcset attribute into the URLs and descriptors of its candidates.
+func scanSrcset(s string) (candidates []srcsetCandidate) {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+	for i := 0; i < len(s); {
+		// Skip whitespace and commas.
+		for i < len(s) && (isSpace(s[i]) || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		start := i
+		for i < len(s) && !isSpace(s[i]) {
+			i++
+		}
+		candidate := srcsetCandidate{start: start, end: i}
+		if s[i-1] == ',' {
+			candidate.end = start + len(strings.TrimRight(s[start:i], ","))
+		} else {
+			// Collect descriptors up to the next comma outside parentheses.
+			var b strings.Builder
+			var paren bool
+		loop:
+			for ; i < len(s); i++ {
+				switch c := s[i]; {
+				case paren:
+					b.WriteByte(c)
+					if c == ')' {
+						paren = false
+					}
+				case c == ',':
+					i++
+					break loop
+				case c == '(':
+					paren = true
+					b.WriteByte(c)
+				default:
+					b.WriteByte(c)
+				}
+			}
+			candidate.descriptors = strings.Fields(b.String())
+		}
+		candidates = append(candidates, candidate)
+	}
+	return
+}
+
+func (c *ImageCandidate) parseDescriptors(descriptors []string) error {
+	var height bool
+	for _, d := range descriptors {
+		if len(d) < 2 {
+			return fmt.Errorf("invalid srcset descriptor %q", d)
+		}
+		value := d[:len(d)-1]
+		switch d[len(d)-1] {
+		case 'w':
+			w, err := strconv.Atoi(value)
+			if err != nil || w <= 0 || c.Width != 0 || c.Density != 0 {
+				return fmt.Errorf("invalid srcset descriptor %q", d)
+			}
+			c.Width = w
+		case 'x':
+			x, err := strconv.ParseFloat(value, 64)
+			if err != nil || x < 0 || c.Width != 0 || c.Density != 0 {
+				return fmt.Errorf("invalid srcset descriptor %q", d)
+			}
+			c.Density = x
+		case 'h':
+			// Height descriptors are only meaningful together with width and are ignored.
+			if h, err := strconv.Atoi(value); err != nil || h <= 0 || height || c.Density != 0 {
+				return fmt.Errorf("invalid srcset descriptor %q", d)
+			}
+			height = true
+		default:
+			return fmt.Errorf("invalid srcset descriptor %q", d)
+		}
+	}
+	if height && c.Width == 0 {
+		return errors.New("height descriptor without width descriptor")
+	}
+	if c.Width == 0 && c.Density == 0 {
+		c.Density = 1
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package node
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSrcset(t *testing.T) {
+	candidates, err := ParseSrcset(" small.jpg 480w,\n large.jpg 1080w, data:image/png;base64,AAA=, retina.jpg 2.5x,plain.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ImageCandidate{
+		{"small.jpg", 480, 0},
+		{"large.jpg", 1080, 0},
+		{"data:image/png;base64,AAA=", 0, 1},
+		{"retina.jpg", 0, 2.5},
+		{"plain.jpg", 0, 1},
+	}
+	if len(candidates) != len(expected) {
+		t.Fatalf("expected candidates %d; got %d", len(expected), len(candidates))
+	}
+	for i, c := range candidates {
+		if c != expected[i] {
+			t.Errorf("expected candidate #%d %v; got %v", i, expected[i], c)
+		}
+	}
+	if s := candidates[3].String(); s != "retina.jpg 2.5x" {
+		t.Errorf("expected string %q; got %q", "retina.jpg 2.5x", s)
+	}
+	for _, s := range []string{"a.jpg 10q", "a.jpg 1x 2x", "a.jpg -5w", "a.jpg 100h", "a.jpg 2x 100h"} {
+		if candidates, err := ParseSrcset(s); err == nil || candidates != nil {
+			t.Errorf("%q: expected error and no candidates; got %v, %v", s, candidates, err)
+		}
+	}
+	candidates, err = ParseSrcset("a.jpg 100w 50h, b.jpg 50h, c.jpg 2x, d.jpg 1y")
+	if err == nil || !strings.Contains(err.Error(), `"b.jpg"`) || !strings.Contains(err.Error(), `"d.jpg"`) {
+		t.Errorf("expected error for b.jpg and d.jpg; got %v", err)
+	}
+	if expected := []ImageCandidate{{"a.jpg", 100, 0}, {"c.jpg", 0, 2}}; !slices.Equal(candidates, expected) {
+		t.Errorf("expected candidates %v; got %v", expected, candidates)
+	}
+}
@@ -1,6 +1,10 @@
 package node
 
-import "regexp"
+import (
+	"regexp"
+
+	"golang.org/x/net/html"
+)
 
 var (
 	_ Filter       = text[string]{}
@@ -40,26 +44,31 @@ func (text[T]) IsString() bool {
 }
 
 // IsMatch returns true if the string filter matches the given node.
+// For comment nodes, the comment's content is matched.
 func (text text[T]) IsMatch(node Node) bool {
-	textNode := node.String()
-	if textNode == nil {
+	var s string
+	if node.Type() == html.CommentNode {
+		s = node.Data()
+	} else if textNode := node.String(); textNode == nil {
 		return false
+	} else {
+		s = textNode.String()
 	}
 	switch v := (any(text.string)).(type) {
 	case string:
-		return textNode.String() == v
+		return s == v
 	case []string:
 		for _, v := range v {
-			if textNode.String() == v {
+			if s == v {
 				return true
 			}
 		}
 	case *regexp.Regexp:
-		return v.MatchString(textNode.String())
+		return v.MatchString(s)
 	case everything:
-		return textNode.String() != ""
+		return s != ""
 	case func(string, Node) bool:
-		return v(textNode.String(), node)
+		return v(s, node)
 	}
 	return false
 }
@@ -0,0 +1,247 @@
+package node
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNotTable is returned by ParseTable when the node is not a <table> element.
+var ErrNotTable = errors.New("node is not a table element")
+
+// TableData is the content of a table normalised into a rectangular grid,
+// where a cell spanning several rows or columns is repeated in each of them.
+type TableData struct {
+	// Header holds the column names. Multiple header rows are merged,
+	// so a "Price" cell spanning "Min" and "Max" gives "Price Min" and "Price Max".
+	// It is nil if the table has no header row.
+	Header []string
+	// Rows holds the text of the cells below the header.
+	Rows [][]string
+}
+
+// ParseTable reads the table element n. Rows of <thead> and leading rows made only of <th>
+// cells are header rows, rows of nested tables are ignored and cell text has its whitespace collapsed.
+func ParseTable(n Node) (*TableData, error) {
+	if n == nil || n.Type() != html.ElementNode || n.Data() != "table" {
+		return nil, ErrNotTable
+	}
+	var rows []*html.Node
+	var header int // number of leading header rows
+	for c := n.Raw().FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "tr":
+			if len(rows) == header && isHeaderRow(c) {
+				header++
+			}
+			rows = append(rows, c)
+		case "thead", "tbody", "tfoot":
+			for tr := c.FirstChild; tr != nil; tr = tr.NextSibling {
+				if tr.Type == html.ElementNode && tr.Data == "tr" {
+					if len(rows) == header && (c.Data == "thead" || isHeaderRow(tr)) {
+						header++
+					}
+					rows = append(rows, tr)
+				}
+			}
+		}
+	}
+	grid := tableGrid(rows)
+	t := &TableData{Rows: grid[header:]}
+	if header > 0 {
+		t.Header = make([]string, len(grid[0]))
+		for i := range t.Header {
+			var names []string
+			for _, row := range grid[:header] {
+				if s := row[i]; s != "" && (len(names) == 0 || names[len(names)-1] != s) {
+					names = append(names, s)
+				}
+			}
+			t.Header[i] = strings.Join(names, " ")
+		}
+	}
+	return t, nil
+}
+
+func isHeaderRow(tr *html.Node) bool {
+	var cells int
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			switch c.Data {
+			case "th":
+				cells++
+			case "td":
+				return false
+			}
+		}
+	}
+	return cells > 0
+}
+
+// tableGrid expands rowspan and colspan, following the limits of the HTML table model.
+func tableGrid(rows []*html.Node) (grid [][]string) {
+	type span struct {
+		text string
+		rows int // remaining rows
+	}
+	var pending []span
+	var width int
+	for r, tr := range rows {
+		var row []string
+		col := 0
+		fill := func() {
+			for col < len(pending) && pending[col].rows > 0 {
+				row = append(row, pending[col].text)
+				pending[col].rows--
+				col++
+			}
+		}
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+			fill()
+			attrs := attributes(c.Attr)
+			colspan, err := attrs.Int("colspan")
+			if err != nil || colspan < 1 {
+				colspan = 1
+			}
+			rowspan, err := attrs.Int("rowspan")
+			if err != nil || rowspan < 0 {
+				rowspan = 1
+			} else if rowspan == 0 {
+				rowspan = len(rows) - r
+			}
+			text := strings.Join(strings.Fields(NewNode(c).GetTextWithOptions(TextOptions{BlockAware: true})), " ")
+			for range min(colspan, 1000) {
+				row = append(row, text)
+				for len(pending) <= col {
+					pending = append(pending, span{})
+				}
+				pending[col] = span{text, min(rowspan, 65534) - 1}
+				col++
+			}
+		}
+		fill()
+		// Spans continuing past a gap in this row still occupy their columns.
+		for ; col < len(pending); col++ {
+			if pending[col].rows > 0 {
+				for len(row) < col {
+					row = append(row, "")
+				}
+				row = append(row, pending[col].text)
+				pending[col].rows--
+			}
+		}
+		width = max(width, len(row))
+		grid = append(grid, row)
+	}
+	for i, row := range grid {
+		grid[i] = append(row, make([]string, width-len(row))...)
+	}
+	return
+}
+
+// Grid returns the header rows merged into one, if any, followed by the other rows.
+func (t *TableData) Grid() [][]string {
+	if t.Header == nil {
+		return t.Rows
+	}
+	return append([][]string{t.Header}, t.Rows...)
+}
+
+// Keys returns the keys used by Records: the header names, with the column number used for empty
+// names or when there is no header. Repeated names and column numbers which clash with a header
+// get the first of "_2", "_3"... appended which makes them unique, so that no column is lost.
+func (t *TableData) Keys() []string {
+	var width int
+	if t.Header != nil {
+		width = len(t.Header)
+	} else if len(t.Rows) > 0 {
+		width = len(t.Rows[0])
+	}
+	// Header names are reserved, so that generated keys never take the name of a later column.
+	reserved := make(map[string]bool)
+	for _, name := range t.Header {
+		reserved[name] = name != ""
+	}
+	keys := make([]string, width)
+	used := make(map[string]bool)
+	for i := range keys {
+		var name string
+		if t.Header != nil {
+			name = t.Header[i]
+		}
+		key := name
+		if name == "" || used[name] {
+			base := name
+			if base == "" {
+				base = strconv.Itoa(i + 1)
+			}
+			key = base
+			for n := 2; used[key] || (key != name && reserved[key]); n++ {
+				key = base + "_" + strconv.Itoa(n)
+			}
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// Records returns each row as a map keyed by Keys.
+func (t *TableData) Records() []map[string]string {
+	keys := t.Keys()
+	records := make([]map[string]string, len(t.Rows))
+	for i, row := range t.Rows {
+		records[i] = make(map[string]string, len(keys))
+		for j, key := range keys {
+			records[i][key] = row[j]
+		}
+	}
+	return records
+}
+
+// WriteCSV writes the table as CSV, starting with the header row if there is one.
+func (t *TableData) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(t.Grid()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteJSON writes the records as a JSON array of objects, keeping the column order.
+func (t *TableData) WriteJSON(w io.Writer) error {
+	keys := t.Keys()
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, row := range t.Rows {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('{')
+		for j, key := range keys {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			v, _ := json.Marshal(row[j])
+			b.Write(k)
+			b.WriteByte(':')
+			b.Write(v)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
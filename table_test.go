@@ -0,0 +1,88 @@
+package node
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	doc, err := ParseHTML(`<table>
+<thead>
+<tr><th rowspan="2">Name</th><th colspan="2">Price</th></tr>
+<tr><th>Min</th><th>Max</th></tr>
+</thead>
+<tbody>
+<tr><td rowspan="2">apple<br>green</td><td>1</td><td>2</td></tr>
+<tr><td colspan="2">"3, 4"</td></tr>
+<tr><td>kiwi</td><td>5</td></tr>
+<tr><td><table><tr><td>nested</td></tr></table></td><td>6</td><td>7</td></tr>
+</tbody>
+</table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := ParseTable(doc.Find(0, Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Name", "Price Min", "Price Max"}; !reflect.DeepEqual(table.Header, expected) {
+		t.Errorf("expected header %q; got %q", expected, table.Header)
+	}
+	expected := [][]string{
+		{"apple green", "1", "2"},
+		{"apple green", `"3, 4"`, `"3, 4"`},
+		{"kiwi", "5", ""},
+		{"nested", "6", "7"},
+	}
+	if !reflect.DeepEqual(table.Rows, expected) {
+		t.Errorf("expected rows %q; got %q", expected, table.Rows)
+	}
+	if records := table.Records(); len(records) != 4 {
+		t.Errorf("expected records %d; got %d", 4, len(records))
+	} else if records[2]["Price Min"] != "5" {
+		t.Errorf("expected value %q; got %q", "5", records[2]["Price Min"])
+	}
+
+	var b strings.Builder
+	if err := table.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	csv := `Name,Price Min,Price Max
+apple green,1,2
+apple green,"""3, 4""","""3, 4"""
+kiwi,5,
+nested,6,7
+`
+	if b.String() != csv {
+		t.Errorf("expected csv %q; got %q", csv, b.String())
+	}
+
+	doc, err = ParseHTML(`<table><tr><th>a</th><th>a</th><th></th></tr><tr><td>1</td><td>2</td><td>3</td></tr></table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table, err = ParseTable(doc.Find(0, Table)); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if err := table.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	if json := `[{"a":"1","a_2":"2","3":"3"}]` + "\n"; b.String() != json {
+		t.Errorf("expected json %q; got %q", json, b.String())
+	}
+
+	table = &TableData{Header: []string{"a", "a", "a_2", "", "4"}, Rows: [][]string{{"1", "2", "3", "4", "5"}}}
+	if keys, expected := table.Keys(), []string{"a", "a_3", "a_2", "4_2", "4"}; !slices.Equal(keys, expected) {
+		t.Errorf("expected keys %q; got %q", expected, keys)
+	}
+	if records := table.Records(); len(records[0]) != 5 {
+		t.Errorf("expected record fields %d; got %d", 5, len(records[0]))
+	}
+
+	if _, err := ParseTable(doc.Find(0, Tr)); err != ErrNotTable {
+		t.Errorf("expected error %v; got %v", ErrNotTable, err)
+	}
+}
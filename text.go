@@ -0,0 +1,122 @@
+package node
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TextOptions controls how GetTextWithOptions joins the text nodes inside a node.
+type TextOptions struct {
+	// Separator is inserted between two consecutive strings.
+	Separator string
+	// Strip removes whitespace at the beginning and end of each string,
+	// and ignores strings consisting entirely of whitespace.
+	Strip bool
+	// CollapseWhitespace replaces each run of whitespace within a string with a single space.
+	CollapseWhitespace bool
+	// BlockAware puts strings on separate lines when they are separated by the boundary of a
+	// block-level element, such as <p>, <div>, <li> or <tr>, and inserts a line break for each <br>,
+	// so that the text keeps its paragraph structure. The separator is not used across lines,
+	// and whitespace-only strings at those boundaries are ignored.
+	BlockAware bool
+	// Visible only includes the text returned by VisibleStrings.
+	Visible bool
+}
+
+// blockElements are the elements whose boundaries start a new line in block-aware text.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "caption": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "head": true, "header": true, "hgroup": true, "hr": true,
+	"html": true, "legend": true, "li": true, "main": true, "menu": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "summary": true, "table": true, "tbody": true,
+	"tfoot": true, "thead": true, "title": true, "tr": true, "ul": true,
+}
+
+func isBlockElement(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockElements[n.Data]
+}
+
+func (n *node) GetTextWithOptions(opts TextOptions) string {
+	var b strings.Builder
+	var started bool
+	var breaks int   // pending line breaks
+	var space string // whitespace written only if no block boundary follows
+	write := func(s string) {
+		if opts.CollapseWhitespace {
+			s = collapseWhitespace(s)
+		}
+		if opts.Strip {
+			if s = strings.TrimSpace(s); s == "" {
+				return
+			}
+		}
+		if opts.BlockAware && strings.TrimSpace(s) == "" {
+			// Whitespace at the boundary of a block is not rendered.
+			if started && breaks == 0 {
+				space += opts.Separator + s
+			}
+			return
+		}
+		if started {
+			if breaks > 0 {
+				b.WriteString(strings.Repeat("\n", breaks))
+			} else {
+				b.WriteString(space + opts.Separator)
+			}
+		}
+		b.WriteString(s)
+		started, breaks, space = true, 0, ""
+	}
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		switch c.Type {
+		case html.TextNode:
+			if !opts.Visible || isVisible(c, n.Raw(), []TagFilter{InvisibleTags}) {
+				write(c.Data)
+			}
+			return
+		case html.ElementNode:
+			if opts.BlockAware && c.Data == "br" {
+				breaks++
+				return
+			}
+		}
+		block := opts.BlockAware && isBlockElement(c) && c != n.Raw()
+		if block {
+			breaks = max(breaks, 1)
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			breaks = max(breaks, 1)
+		}
+	}
+	walk(n.Raw())
+	if breaks == 0 && !isBlockElement(n.Raw()) {
+		b.WriteString(space)
+	}
+	return b.String()
+}
+
+// collapseWhitespace replaces each run of whitespace with a single space.
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	var space bool
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+		default:
+			b.WriteRune(r)
+			space = false
+		}
+	}
+	return b.String()
+}
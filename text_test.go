@@ -0,0 +1,38 @@
+package node
+
+import "testing"
+
+func TestGetTextWithOptions(t *testing.T) {
+	doc, err := ParseHTML(`<body><h1>Title</h1>
+<p>First   paragraph
+with <b>bold</b> text.</p>
+<ul><li>a</li><li>b</li></ul>
+<div>line 1<br>line 2<br><br>line 4</div>
+<table><tr><td>x</td><td>y</td></tr><tr><td>z</td></tr></table>
+<script>var hidden;</script>
+</body>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := doc.Find(0, Body)
+	for _, tc := range []struct {
+		node     Node
+		opts     TextOptions
+		expected string
+	}{
+		{doc.Find(0, Tag("ul")), TextOptions{}, "ab"},
+		{doc.Find(0, Tag("ul")), TextOptions{Separator: ", "}, "a, b"},
+		{doc.Find(0, Tag("ul")), TextOptions{BlockAware: true}, "a\nb"},
+		{doc.Find(0, P), TextOptions{Separator: " ", Strip: true, CollapseWhitespace: true}, "First paragraph with bold text."},
+		{doc.Find(0, Div), TextOptions{BlockAware: true}, "line 1\nline 2\n\nline 4"},
+		{doc.Find(0, Table), TextOptions{Separator: "\t", BlockAware: true}, "x\ty\nz"},
+		{mustFragment(t, "<div> <p>a</p>\n<p>b <i>c</i></p>\n</div>"), TextOptions{BlockAware: true}, "a\nb c"},
+		{mustFragment(t, "<span>a<i> </i></span>"), TextOptions{Separator: "|", BlockAware: true}, "a| "},
+		{body, TextOptions{Separator: " ", Strip: true, CollapseWhitespace: true, BlockAware: true, Visible: true},
+			"Title\nFirst paragraph with bold text.\na\nb\nline 1\nline 2\n\nline 4\nx y\nz"},
+	} {
+		if text := tc.node.GetTextWithOptions(tc.opts); text != tc.expected {
+			t.Errorf("%+v: expected text %q; got %q", tc.opts, tc.expected, text)
+		}
+	}
+}
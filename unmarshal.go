@@ -0,0 +1,364 @@
+package node
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNoMatch is reported for a required field when its selector matches no node.
+var ErrNoMatch = errors.New("no node matched")
+
+// UnmarshalError describes a field which could not be decoded.
+type UnmarshalError struct {
+	Field string // path of the field, e.g. "Item[2].Price"
+	Value string // text which could not be converted, if any
+	Err   error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Value != "" {
+		return fmt.Sprintf("node: cannot unmarshal %q into %s: %v", e.Value, e.Field, e.Err)
+	}
+	return fmt.Sprintf("node: %s: %v", e.Field, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal decodes the parse tree rooted at n into the value pointed to by v,
+// which must be a struct, a slice of structs or a pointer to one.
+//
+// Fields are decoded according to their "node" tag, a comma-separated list of options:
+//
+//	css=SELECTOR   select nodes with a css selector
+//	xpath=EXPR     select nodes with an XPath expression
+//	tag=NAME       find descendants by tag name, like Find(Descendant, Tag(NAME))
+//	class=NAME     find descendants by class, may be combined with tag and id
+//	id=NAME        find descendants by id, may be combined with tag and class
+//	text           decode the text of the node with surrounding whitespace removed (the default)
+//	html           decode the inner HTML of the node
+//	attr=NAME      decode the value of an attribute, nodes without it are ignored
+//	required       report ErrNoMatch if nothing is found instead of leaving the zero value
+//
+// Without a selector the field is decoded from the current node. Untagged fields are ignored,
+// except embedded structs, which are decoded from the current node.
+//
+// A slice receives every matched node, any other field the first one. A struct or slice of structs
+// is decoded with the matched nodes as the new current node. The items of a slice of structs can
+// also be selected by the tag of a blank field in the struct, e.g. _ struct{} `node:"css=.item"`,
+// which is how a top-level slice is decoded.
+//
+// Text is converted to strings, booleans, integers, floats and types implementing
+// encoding.TextUnmarshaler. Fields of type Node or HtmlNode receive the matched node itself.
+func Unmarshal(n HtmlNode, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("node: Unmarshal(non-pointer %v)", reflect.TypeOf(v))
+	}
+	t := rv.Type().Elem()
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return decode(n, rv.Elem(), &fieldTag{}, t.Name())
+}
+
+// fieldTag is a parsed "node" struct tag.
+type fieldTag struct {
+	sel      Selector // nil selects the current node
+	html     bool
+	attr     string
+	hasAttr  bool
+	required bool
+}
+
+var tagKeys = map[string]bool{
+	"css": true, "xpath": true, "tag": true, "class": true, "id": true, "attr": true,
+	"text": true, "html": true, "required": true,
+}
+
+func parseTag(s string) (*fieldTag, error) {
+	// Selectors may contain commas, so a segment only starts an option if it begins with a known key.
+	var options []string
+	for segment := range strings.SplitSeq(s, ",") {
+		key, _, _ := strings.Cut(strings.TrimSpace(segment), "=")
+		if tagKeys[key] || len(options) == 0 {
+			options = append(options, segment)
+		} else {
+			options[len(options)-1] += "," + segment
+		}
+	}
+	tag := new(fieldTag)
+	var find []Filter
+	var findTag TagFilter
+	var hasFind bool
+	for _, option := range options {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+		if !tagKeys[key] {
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+		if hasValue != (key != "text" && key != "html" && key != "required") || (hasValue && value == "") {
+			return nil, fmt.Errorf("invalid option %q", option)
+		}
+		var err error
+		switch key {
+		case "css", "xpath":
+			if tag.sel != nil || hasFind {
+				return nil, errors.New("multiple selectors")
+			}
+			if key == "css" {
+				tag.sel, err = CompileCSS(value)
+			} else {
+				tag.sel, err = CompileXPath(value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", key, value, err)
+			}
+		case "tag", "class", "id":
+			if tag.sel != nil {
+				return nil, errors.New("multiple selectors")
+			}
+			hasFind = true
+			switch key {
+			case "tag":
+				findTag = Tag(value)
+			case "class":
+				find = append(find, Class(value))
+			case "id":
+				find = append(find, Id(value))
+			}
+		case "attr":
+			tag.attr, tag.hasAttr = value, true
+		case "html":
+			tag.html = true
+		case "required":
+			tag.required = true
+		}
+	}
+	if tag.html && tag.hasAttr {
+		return nil, errors.New("both html and attr")
+	}
+	if hasFind {
+		tag.sel = FindSelector(findTag, find...)
+	}
+	return tag, nil
+}
+
+type structField struct {
+	index int
+	name  string
+	tag   *fieldTag // nil for embedded structs decoded from the current node
+}
+
+type structInfo struct {
+	fields []structField
+	items  Selector // from the tag of a blank field
+}
+
+var structCache sync.Map // map[reflect.Type]*structInfo
+
+func getStructInfo(t reflect.Type) (*structInfo, error) {
+	if info, ok := structCache.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+	info := new(structInfo)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		s, ok := f.Tag.Lookup("node")
+		if s == "-" || (!ok && !(f.Anonymous && f.Type.Kind() == reflect.Struct)) {
+			continue
+		}
+		var tag *fieldTag
+		if ok {
+			var err error
+			if tag, err = parseTag(s); err != nil {
+				return nil, &UnmarshalError{Field: fieldPath(t.Name(), f.Name), Err: err}
+			}
+		}
+		if f.Name == "_" {
+			info.items = tag.sel
+		} else if f.IsExported() {
+			info.fields = append(info.fields, structField{i, f.Name, tag})
+		}
+	}
+	structCache.Store(t, info)
+	return info, nil
+}
+
+var (
+	nodeType            = reflect.TypeFor[Node]()
+	htmlNodeType        = reflect.TypeFor[HtmlNode]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// decode decodes the nodes selected by tag from n into v.
+func decode(n HtmlNode, v reflect.Value, tag *fieldTag, path string) error {
+	nodes := []Node{n.ToNode()}
+	if tag.sel != nil {
+		nodes = tag.sel.SelectAll(n)
+	}
+	if tag.hasAttr {
+		var withAttr []Node
+		for _, n := range nodes {
+			if n.HasAttr(tag.attr) {
+				withAttr = append(withAttr, n)
+			}
+		}
+		nodes = withAttr
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		elem := v.Type().Elem()
+		if tag.sel == nil {
+			if t := indirect(elem); t.Kind() == reflect.Struct && !isScalar(t) {
+				info, err := getStructInfo(t)
+				if err != nil {
+					return err
+				}
+				if info.items == nil {
+					return &UnmarshalError{Field: path, Err: fmt.Errorf("no selector for items of %v", v.Type())}
+				}
+				nodes = info.items.SelectAll(n)
+			}
+		}
+		if len(nodes) == 0 && tag.required {
+			return &UnmarshalError{Field: path, Err: ErrNoMatch}
+		}
+		s := reflect.MakeSlice(v.Type(), len(nodes), len(nodes))
+		for i, n := range nodes {
+			if err := decodeValue(n, s.Index(i), tag, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	if len(nodes) == 0 {
+		if tag.required {
+			return &UnmarshalError{Field: path, Err: ErrNoMatch}
+		}
+		return nil
+	}
+	return decodeValue(nodes[0], v, tag, path)
+}
+
+// decodeValue decodes the node n itself into v.
+func decodeValue(n Node, v reflect.Value, tag *fieldTag, path string) error {
+	if t := v.Type(); t == nodeType || t == htmlNodeType {
+		v.Set(reflect.ValueOf(n))
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(n, v.Elem(), tag, path)
+	}
+	if v.Kind() == reflect.Struct && !isScalar(v.Type()) {
+		info, err := getStructInfo(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, f := range info.fields {
+			tag := f.tag
+			if tag == nil {
+				tag = &fieldTag{}
+			}
+			if err := decode(n, v.Field(f.index), tag, fieldPath(path, f.name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var s string
+	switch {
+	case tag.hasAttr:
+		s, _ = n.Attrs().Get(tag.attr)
+	case tag.html:
+		s = innerHTML(n.Raw())
+	default:
+		s = strings.TrimSpace(n.GetText())
+	}
+	if err := setScalar(v, s); err != nil {
+		return &UnmarshalError{Field: path, Value: s, Err: err}
+	}
+	return nil
+}
+
+// fieldPath joins a field name to the path of its struct, which is empty for anonymous structs.
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isScalar reports whether values of type t are decoded from text.
+func isScalar(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setScalar(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			break
+		}
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+func innerHTML(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&b, c)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	_      struct{} `node:"css=.item"`
+	Title  string   `node:"css=h2"`
+	Price  float64  `node:"css=.price"`
+	Link   string   `node:"css=a,attr=href"`
+	Tags   []string `node:"class=tag"`
+	Stock  *int     `node:"xpath=.//span[@class='stock']"`
+	Seller struct {
+		Name  string    `node:"tag=b"`
+		Since time.Time `node:"css=time,attr=datetime"`
+	} `node:"css=.seller"`
+	Desc string `node:"css=.desc,html"`
+	Node Node   `node:"css=h2, h3"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	doc, err := ParseHTML(`<div class="item">
+<h2> Apple </h2><span class="price">1.5</span><a href="/apple">more</a>
+<span class="tag">fruit</span><span class="tag">red</span><span class="stock">7</span>
+<div class="seller"><b>Farm</b><time datetime="2024-01-02T00:00:00Z">Jan 2</time></div>
+<p class="desc">Fresh <i>and</i> crisp</p>
+</div>
+<div class="item"><h3>Kiwi</h3><span class="price">2</span></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []testItem
+	if err := Unmarshal(doc, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected items %d; got %d", 2, len(items))
+	}
+	item := items[0]
+	if item.Title != "Apple" || item.Price != 1.5 || item.Link != "/apple" {
+		t.Errorf("expected Apple 1.5 /apple; got %q %v %q", item.Title, item.Price, item.Link)
+	}
+	if expected := []string{"fruit", "red"}; !reflect.DeepEqual(item.Tags, expected) {
+		t.Errorf("expected tags %q; got %q", expected, item.Tags)
+	}
+	if item.Stock == nil || *item.Stock != 7 {
+		t.Errorf("expected stock 7; got %v", item.Stock)
+	}
+	if item.Seller.Name != "Farm" || !item.Seller.Since.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected seller %+v", item.Seller)
+	}
+	if expected := "Fresh <i>and</i> crisp"; item.Desc != expected {
+		t.Errorf("expected desc %q; got %q", expected, item.Desc)
+	}
+	if item.Node == nil || item.Node.Data() != "h2" {
+		t.Errorf("expected node h2; got %v", item.Node)
+	}
+	if items[1].Title != "" || items[1].Price != 2 || items[1].Stock != nil || items[1].Node.Data() != "h3" {
+		t.Errorf("unexpected item %+v", items[1])
+	}
+
+	var bad struct {
+		Items []struct {
+			Price int `node:"css=.price"`
+		} `node:"css=.item"`
+	}
+	err = Unmarshal(doc, &bad)
+	var e *UnmarshalError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected UnmarshalError; got %v", err)
+	}
+	if e.Field != "Items[0].Price" || e.Value != "1.5" {
+		t.Errorf("expected field %q value %q; got %q %q", "Items[0].Price", "1.5", e.Field, e.Value)
+	}
+
+	var required struct {
+		Missing string `node:"css=.missing,required"`
+	}
+	if err := Unmarshal(doc, &required); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected error %v; got %v", ErrNoMatch, err)
+	}
+	var invalid struct {
+		Bad string `node:"css=[,attr=x"`
+	}
+	if err := Unmarshal(doc, &invalid); err == nil || !strings.Contains(err.Error(), "Bad") {
+		t.Errorf("expected error naming field; got %v", err)
+	}
+	if err := Unmarshal(doc, items); err == nil {
+		t.Error("expected error for non-pointer; got nil")
+	}
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// InvisibleTags matches the elements whose text is never rendered.
+// It is used by VisibleStrings and VisibleText when no exclude filter is given.
+var InvisibleTags = Tags("script", "style", "template", "noscript")
+
+func (n *node) VisibleStrings(exclude ...TagFilter) (strings []TextNode) {
+	if len(exclude) == 0 {
+		exclude = []TagFilter{InvisibleTags}
+	}
+	for _, i := range n.Strings() {
+		if isVisible(i.Raw(), n.Raw(), exclude) {
+			strings = append(strings, i)
+		}
+	}
+	return
+}
+
+func (n *node) VisibleText(exclude ...TagFilter) string {
+	var b strings.Builder
+	for _, i := range n.VisibleStrings(exclude...) {
+		b.WriteString(i.String())
+	}
+	return b.String()
+}
+
+// isVisible reports whether none of the ancestors of text up to and including root
+// is excluded or hidden.
+func isVisible(text, root *html.Node, exclude []TagFilter) bool {
+	for p := text.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode {
+			if isHidden(p) {
+				return false
+			}
+			node := NewNode(p)
+			for _, f := range exclude {
+				if f != nil && f.IsMatch(node) {
+					return false
+				}
+			}
+		}
+		if p == root {
+			break
+		}
+	}
+	return true
+}
+
+// isHidden reports whether the element is hidden by the hidden attribute,
+// aria-hidden="true" or an inline display:none style.
+func isHidden(n *html.Node) bool {
+	attrs := attributes(n.Attr)
+	if attrs.has("hidden") {
+		return true
+	}
+	if v, ok := attrs.Get("aria-hidden"); ok && strings.EqualFold(strings.TrimSpace(v), "true") {
+		return true
+	}
+	if style, ok := attrs.Get("style"); ok {
+		for decl := range strings.SplitSeq(style, ";") {
+			prop, value, ok := strings.Cut(decl, ":")
+			if !ok || !strings.EqualFold(strings.TrimSpace(prop), "display") {
+				continue
+			}
+			value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+			if strings.EqualFold(value, "none") {
+				return true
+			}
+		}
+	}
+	return false
+}
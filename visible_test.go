@@ -0,0 +1,28 @@
+package node
+
+import "testing"
+
+func TestVisibleText(t *testing.T) {
+	doc, err := ParseHTML(`<html><head><style>p{}</style><script>var a;</script></head><body>
+<p>one</p><noscript>enable js</noscript><template><p>tpl</p></template>
+<div hidden>h1</div><div aria-hidden="true">h2</div><div style="color: red; display : NONE !important">h3</div>
+<div style="display:block">two</div><nav>menu</nav></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := doc.Find(0, Body)
+	if text := body.VisibleText(); text != "\none\n\ntwomenu" {
+		t.Errorf("expected text %q; got %q", "\none\n\ntwomenu", text)
+	}
+	if strings := body.VisibleStrings(InvisibleTags, Tag("nav")); len(strings) != 5 {
+		t.Errorf("expected strings %d; got %d", 5, len(strings))
+	} else if s := strings[4].String(); s != "two" {
+		t.Errorf("expected string %q; got %q", "two", s)
+	}
+	if text := doc.Find(0, Tag("noscript")).VisibleText(Tag("nav")); text != "enable js" {
+		t.Errorf("expected text %q; got %q", "enable js", text)
+	}
+	if text := doc.Find(0, Div).VisibleText(); text != "" {
+		t.Errorf("expected empty text; got %q", text)
+	}
+}